@@ -2,10 +2,13 @@ package main
 
 import (
 	"github.com/ikari-pl/golangci-lint-temporalio/pkg/callables"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/queries"
 	"github.com/ikari-pl/golangci-lint-temporalio/pkg/serializable"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/signals"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/updates"
 	"golang.org/x/tools/go/analysis/multichecker"
 )
 
 func main() {
-	multichecker.Main(callables.Analyzer, serializable.Analyzer)
+	multichecker.Main(callables.Analyzer, serializable.Analyzer, signals.Analyzer, queries.Analyzer, updates.Analyzer)
 }
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"go/ast"
 	goTypes "go/types"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
 
 	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
 	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
@@ -16,11 +20,14 @@ import (
 )
 
 var Analyzer = &analysis.Analyzer{
-	Name:       "TemporalIoCallables",
-	Doc:        "Detects registrations of, and calls to Temporal.io workflows and activities",
-	Run:        run,
-	Flags:      tcFlags,
-	FactTypes:  []analysis.Fact{new(isWorkflow), new(isActivity), new(isWorkflowCall), new(isActivityCall)},
+	Name:  "TemporalIoCallables",
+	Doc:   "Detects registrations of, and calls to Temporal.io workflows and activities",
+	Run:   run,
+	Flags: tcFlags,
+	FactTypes: []analysis.Fact{
+		new(isWorkflow), new(isActivity), new(isWorkflowCall), new(isActivityCall),
+		new(registrationsFact),
+	},
 	ResultType: reflect.TypeOf(Callables{}),
 }
 
@@ -29,10 +36,18 @@ var (
 	debug   bool
 )
 
+// Registration is a workflow or an activity registered on a worker.
 type Registration struct {
-	Call            ast.CallExpr
+	Call ast.CallExpr
+	// CalleeSignature is the signature to verify, nil if it can't be resolved (or has been verified already)
 	CalleeSignature *goTypes.Signature
 	Type            types.TemporalIoCallType
+	// Name is the name Temporal registers the workflow/activity under, empty if it can't be determined
+	Name string
+	// Callee is the registered function (or method), nil if it can't be resolved
+	Callee goTypes.Object
+	// TaskQueue is the task queue of the worker, empty if it can't be determined
+	TaskQueue string
 }
 
 func init() {
@@ -40,58 +55,183 @@ func init() {
 }
 
 // run is the main function of the analyzer,
-// it identifies workflows and activities and exports them as Object Facts,
-// as well as returning them as a result.
+// it identifies workflows and activities and exports them as facts,
+// as well as returning them (and the ones registered in imported packages) as a result.
 func run(pass *analysis.Pass) (interface{}, error) {
-	workflows, activities, registrations := identify(pass)
-	export(pass, workflows, activities)
+	result := identify(pass)
+	export(pass, result.Registry)
 
 	// now let's identify calls to these workflows and activities
-	for _, r := range registrations {
+	for _, r := range result.Registrations {
 		checkCalleeMatchesRegistration(pass, r)
 	}
-	return Callables{
-		Workflows:  workflows,
-		Activities: activities,
-	}, nil
-}
-
-// export exports the identified workflows and activities as facts
-// so that they can be used by other analyzers
-// (note: sometimes we panic, in which case the export is not successful)
-func export(pass *analysis.Pass, workflows []goTypes.Object, activities []goTypes.Object) {
-	defer func() {
-		// bug: we panic sometimes, let's recover
-		if r := recover(); r != nil {
-			fmt.Printf("WARN: Recovered from panic: %v\n", r)
-		}
-	}()
-	for _, v := range workflows {
-		// make sure v belongs to the package we're analyzing
-		if v.Pkg() != pass.Pkg {
+	checkCollisions(pass, result.Registrations)
+	checkDeclarations(pass, result.Registrations)
+
+	// names registered locally take precedence over the imported ones
+	registry := importRegistry(pass)
+	for _, r := range result.Registrations {
+		if r.Callee != nil {
+			registry.add(r.Type, r.Name, r.Callee)
+			if r.TaskQueue != "" {
+				registry.addOnQueue(r.TaskQueue, r.Type, r.Name, r.Callee)
+			}
+		}
+	}
+	for queue := range result.Registry.partialQueues {
+		registry.partialQueues[queue] = true
+	}
+	result.Registry = registry
+	return result, nil
+}
+
+// export exports the identified workflows and activities as facts, so that they can be used by the analyzers
+// of the packages importing this one: object facts for the functions declared in this package,
+// and a package fact for all the registrations (on any worker, and on the workers of each known task queue).
+func export(pass *analysis.Pass, registry Registry) {
+	fact := registrationsFact{Registrations: registrations(registry, "")}
+	for _, queue := range slices.Sorted(maps.Keys(registry.queues)) {
+		fact.Registrations = append(fact.Registrations, registrations(registry.queues[queue], queue)...)
+	}
+	fact.PartialQueues = slices.Sorted(maps.Keys(registry.partialQueues))
+
+	workflowNames := map[goTypes.Object][]string{}
+	activityNames := map[goTypes.Object][]string{}
+	for _, r := range fact.Registrations {
+		if r.Pkg != pass.Pkg.Path() || r.TaskQueue != "" {
 			continue
 		}
-		pass.ExportObjectFact(v, new(isActivity))
+		o := registry.Lookup(r.Type, r.Name)
+		if r.Type == types.Workflow {
+			workflowNames[o] = append(workflowNames[o], r.Name)
+		} else {
+			activityNames[o] = append(activityNames[o], r.Name)
+		}
+	}
+	for o, names := range workflowNames {
+		pass.ExportObjectFact(o, &isWorkflow{Names: names})
 		if isDebug() {
-			fmt.Printf("Workflow: %s\n", v)
+			fmt.Printf("Workflow: %s %v\n", o, names)
+		}
+	}
+	for o, names := range activityNames {
+		pass.ExportObjectFact(o, &isActivity{Names: names})
+		if isDebug() {
+			fmt.Printf("Activity: %s %v\n", o, names)
+		}
+	}
+	if len(fact.Registrations) > 0 || len(fact.PartialQueues) > 0 {
+		pass.ExportPackageFact(&fact)
+	}
+}
+
+// registrations returns the named registrations of the registry that can be referred to from other packages,
+// on the workers of the task queue (or on any worker, if it's empty).
+func registrations(registry Registry, queue string) []registered {
+	var result []registered
+	for _, t := range []types.TemporalIoCallType{types.Workflow, types.Activity} {
+		byName := registry.Workflows
+		if t == types.Activity {
+			byName = registry.Activities
+		}
+		for _, name := range slices.Sorted(maps.Keys(byName)) {
+			o := byName[name]
+			if o == nil || o.Pkg() == nil {
+				continue
+			}
+			// function literals, local variables etc. cannot be referred to from other packages
+			path, err := objectpath.For(o)
+			if err != nil {
+				continue
+			}
+			result = append(result, registered{
+				Type:      t,
+				Name:      name,
+				Pkg:       o.Pkg().Path(),
+				Path:      path,
+				Signature: o.Type().String(),
+				TaskQueue: queue,
+			})
 		}
 	}
-	for _, v := range activities {
-		if v.Pkg() != pass.Pkg {
+	return result
+}
+
+// importRegistry returns the registry of workflows and activities registered in the imported packages,
+// from their facts.
+func importRegistry(pass *analysis.Pass) Registry {
+	registry := newRegistry()
+	for _, f := range pass.AllObjectFacts() {
+		switch fact := f.Fact.(type) {
+		case *isWorkflow:
+			for _, name := range fact.Names {
+				registry.add(types.Workflow, name, f.Object)
+			}
+		case *isActivity:
+			for _, name := range fact.Names {
+				registry.add(types.Activity, name, f.Object)
+			}
+		}
+	}
+
+	packages := asttools.ImportedPackages(pass.Pkg)
+	for _, f := range pass.AllPackageFacts() {
+		fact, ok := f.Fact.(*registrationsFact)
+		if !ok || f.Package == pass.Pkg {
 			continue
 		}
-		pass.ExportObjectFact(v, new(isWorkflow))
-		if isDebug() {
-			fmt.Printf("Activity: %s\n", v)
+		for _, r := range fact.Registrations {
+			pkg, ok := packages[r.Pkg]
+			if !ok {
+				continue
+			}
+			o, err := objectpath.Object(pkg, r.Path)
+			if err != nil {
+				if isDebug() {
+					fmt.Printf("Cannot resolve %s registered in %s: %v\n", r.Name, f.Package.Path(), err)
+				}
+				continue
+			}
+			if r.TaskQueue != "" {
+				registry.addOnQueue(r.TaskQueue, r.Type, r.Name, o)
+			} else {
+				registry.add(r.Type, r.Name, o)
+			}
+		}
+		for _, queue := range fact.PartialQueues {
+			registry.partialQueues[queue] = true
 		}
 	}
+	return registry
 }
 
 func isDebug() bool {
 	return debug
 }
 
-func identify(pass *analysis.Pass) (workflows, activities []goTypes.Object, registerCalls []Registration) {
+func identify(pass *analysis.Pass) Callables {
+	result := Callables{
+		Registry:            newRegistry(),
+		UnregisteredMethods: map[goTypes.Object]string{},
+	}
+	register := func(r Registration) {
+		result.Registrations = append(result.Registrations, r)
+		if r.Callee == nil {
+			return
+		}
+		switch r.Type {
+		case types.Workflow:
+			result.Workflows = append(result.Workflows, r.Callee)
+		case types.Activity:
+			result.Activities = append(result.Activities, r.Callee)
+		}
+		result.Registry.add(r.Type, r.Name, r.Callee)
+		if r.TaskQueue != "" {
+			result.Registry.addOnQueue(r.TaskQueue, r.Type, r.Name, r.Callee)
+		}
+	}
+
+	values := asttools.AssignedValues(pass.TypesInfo, pass.Files)
 	for _, f := range pass.Files {
 		ast.Inspect(f, func(n ast.Node) bool {
 			var t types.TemporalIoCallType
@@ -99,32 +239,31 @@ func identify(pass *analysis.Pass) (workflows, activities []goTypes.Object, regi
 			if !isRegisterCall {
 				return true
 			}
+			queue := workerQueue(pass, values, callExpr.Fun.(*ast.SelectorExpr).X)
+			// activities can be registered as a struct, in which case each of its methods is an activity
+			if methodName == external.RegisterActivity || methodName == external.RegisterActivityWithOptions {
+				if structType := registeredStruct(pass, callExpr); structType != nil {
+					methods := identifyActivityStruct(pass, callExpr, structType)
+					for _, name := range slices.Sorted(maps.Keys(methods)) {
+						// the methods have been verified while identifying them
+						register(Registration{
+							Call: *callExpr, Type: types.Activity, Name: name, Callee: methods[name], TaskQueue: queue,
+						})
+					}
+					if _, isPtr := structType.(*goTypes.Pointer); !isPtr {
+						for _, m := range methodsMissingFromValue(pass, callExpr, structType) {
+							result.UnregisteredMethods[m] = goTypes.TypeString(structType, goTypes.RelativeTo(pass.Pkg))
+						}
+					}
+					return true
+				}
+			}
 			switch methodName {
-			case external.RegisterWorkflow:
+			// you can also register a workflow or an activity under the name of your choice with the options
+			case external.RegisterWorkflow, external.RegisterWorkflowWithOptions:
 				t = types.Workflow
-				firstArgObj := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(callExpr.Args[0]))
-				workflows = append(workflows, firstArgObj)
-			case external.RegisterActivity:
+			case external.RegisterActivity, external.RegisterActivityWithOptions:
 				t = types.Activity
-				firstArgObj := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(callExpr.Args[0]))
-				activities = append(activities, firstArgObj)
-			// you can also register an activity under the name of your choice with RegisterActivityWithOptions
-			case external.RegisterActivityWithOptions:
-				firstArgObj := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(callExpr.Args[0]))
-				optionsArgObj := asttools.IdentifierOf(callExpr.Args[1])
-				// optionsArgObj is a struct of type worker.RegisterActivityOptions,
-				// let's check if it has a Name field
-				alt := identifyAsNamed(optionsArgObj, pass, callExpr, firstArgObj)
-				if alt != nil {
-					activities = append(activities, alt)
-				}
-				if firstArgObj == nil {
-					// can be an inline function, but we don't support that
-					position := pass.Fset.Position(callExpr.Pos())
-					_, _ = fmt.Fprintln(os.Stderr, fmt.Sprintf("WARN: first arg of RegisterActivityWithOptions is not a function: will not analyze this activity:\n\t %s", position))
-				} else {
-					activities = append(activities, firstArgObj)
-				}
 			default:
 				t = types.NotSupported
 			}
@@ -132,22 +271,110 @@ func identify(pass *analysis.Pass) (workflows, activities []goTypes.Object, regi
 				t2 := pass.TypesInfo.TypeOf(callExpr.Fun)
 				fmt.Printf("Type of %s is %s\n", callExpr.Fun, t2)
 			}
+			if t == types.NotSupported {
+				return true
+			}
 
+			firstArgObj := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(callExpr.Args[0]))
+			if firstArgObj == nil {
+				// can be an inline function, but we don't support that
+				position := pass.Fset.Position(callExpr.Pos())
+				_, _ = fmt.Fprintln(os.Stderr, fmt.Sprintf("WARN: first arg of %s is not a function: will not analyze it:\n\t %s", methodName, position))
+			}
+			r := Registration{Call: *callExpr, Type: t, Callee: firstArgObj, TaskQueue: queue}
+			r.Name, _ = registeredName(pass, values, callExpr, firstArgObj)
 			// for now, we only verify the signatures we can resolve (functions, not their names, passed as arguments)
-			if t != types.NotSupported {
-				sig, isSig := pass.TypesInfo.TypeOf(callExpr.Args[0]).(*goTypes.Signature)
-				if isSig {
-					registerCalls = append(registerCalls, Registration{
-						Call:            *callExpr,
-						CalleeSignature: sig,
-						Type:            t,
-					})
+			if sig, isSig := pass.TypesInfo.TypeOf(callExpr.Args[0]).(*goTypes.Signature); isSig {
+				r.CalleeSignature = sig
+			}
+			register(r)
+			return true
+		})
+	}
+	queues, passed := partialQueues(pass, values)
+	for queue := range queues {
+		result.Registry.partialQueues[queue] = true
+	}
+	result.OwnsWorkers = !passed && createsWorkers(pass)
+	return result
+}
+
+// workerQueue returns the task queue of the worker, if it's created with a constant one:
+// w := worker.New(temporalClient, "queue", worker.Options{})
+func workerQueue(pass *analysis.Pass, values asttools.Values, worker ast.Expr) string {
+	call, ok := values.At(worker).(*ast.CallExpr)
+	if !ok || len(call.Args) < 2 || !asttools.IsFuncCall(pass.TypesInfo, call, external.WorkerPkg, external.NewWorker) {
+		return ""
+	}
+	queue, _ := asttools.StringValue(pass.TypesInfo, call.Args[1])
+	return queue
+}
+
+// partialQueues returns the task queues of the workers passed to other functions, e.g. activities.Register(w),
+// and whether any worker is (including the ones whose task queue is not known).
+// They may register more workflows and activities on them, which cannot be attributed to the task queue.
+func partialQueues(pass *analysis.Pass, values asttools.Values) (map[string]bool, bool) {
+	queues := map[string]bool{}
+	passed := false
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			for _, arg := range call.Args {
+				t := pass.TypesInfo.TypeOf(arg)
+				if t == nil || t.String() != external.WorkerType {
+					continue
+				}
+				passed = true
+				if queue := workerQueue(pass, values, arg); queue != "" {
+					queues[queue] = true
 				}
 			}
 			return true
 		})
 	}
-	return workflows, activities, registerCalls
+	return queues, passed
+}
+
+// createsWorkers returns true if the package creates workers with worker.New.
+func createsWorkers(pass *analysis.Pass) bool {
+	for _, f := range pass.Files {
+		found := false
+		ast.Inspect(f, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && asttools.IsFuncCall(pass.TypesInfo, call, external.WorkerPkg, external.NewWorker) {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// registeredName returns the name Temporal registers the workflow/activity under:
+// the Name from the registration options, if set, otherwise the name of the function (or method).
+// Returns false if the name cannot be determined statically, e.g. the options are built by another function.
+func registeredName(pass *analysis.Pass, values asttools.Values, callExpr *ast.CallExpr, obj goTypes.Object) (string, bool) {
+	if len(callExpr.Args) > 1 {
+		options := values.At(callExpr.Args[1])
+		if _, ok := ast.Unparen(options).(*ast.CompositeLit); !ok {
+			return "", false
+		}
+		if nameExpr := asttools.FieldValue(options, "Name"); nameExpr != nil {
+			name, ok := asttools.StringValue(pass.TypesInfo, nameExpr)
+			if !ok || name != "" {
+				return name, ok
+			}
+		}
+	}
+	if obj == nil {
+		return "", false
+	}
+	return obj.Name(), true
 }
 
 func asRegisterCall(n ast.Node, pass *analysis.Pass) (*ast.CallExpr, string, bool) {
@@ -177,40 +404,109 @@ func asRegisterCall(n ast.Node, pass *analysis.Pass) (*ast.CallExpr, string, boo
 	return callExpr, selector.Sel.Name, true
 }
 
-// identifyAsNamed checks if the options object has a Name field and returns an alternative object
-// representing the activity with that name.
-func identifyAsNamed(optionsArgObj *ast.Ident,
-	pass *analysis.Pass,
-	callExpr *ast.CallExpr,
-	firstArgObj goTypes.Object,
-) goTypes.Object {
-	if optionsArgObj == nil {
+// registeredStruct returns the type of the struct registered as activities by the call,
+// or nil if it's not a struct registration.
+func registeredStruct(pass *analysis.Pass, callExpr *ast.CallExpr) goTypes.Type {
+	if len(callExpr.Args) < 1 {
 		return nil
 	}
-	optionsType, ok := pass.TypesInfo.Types[callExpr.Args[1]]
-	if !ok {
+	t := pass.TypesInfo.TypeOf(callExpr.Args[0])
+	if t == nil {
 		return nil
 	}
-	if optionsType.Type.String() != "go.temporal.io/sdk/worker.RegisterActivityOptions" {
-		return nil
+	underlying := t.Underlying()
+	if ptr, ok := t.(*goTypes.Pointer); ok {
+		underlying = ptr.Elem().Underlying()
 	}
-	t, isStruct := optionsType.Type.Underlying().(*goTypes.Struct)
-	if !isStruct {
-		return nil
+	if _, isStruct := underlying.(*goTypes.Struct); isStruct {
+		return t
 	}
-	for i := range t.NumFields() {
-		field := t.Field(i)
-		if field.Name() == "Name" {
-			sign, isSign := firstArgObj.Type().(*goTypes.Signature)
-			if !isSign {
-				return nil
+	return nil
+}
+
+// methodsMissingFromValue returns the exported methods with pointer receivers of a struct registered by value,
+// and reports them: they are not in the method set of the value, so they are not registered.
+func methodsMissingFromValue(pass *analysis.Pass, callExpr *ast.CallExpr, structType goTypes.Type) []goTypes.Object {
+	var missing []goTypes.Object
+	var names []string
+	values := goTypes.NewMethodSet(structType)
+	pointers := goTypes.NewMethodSet(goTypes.NewPointer(structType))
+	for i := range pointers.Len() {
+		method := pointers.At(i).Obj()
+		if method.Exported() && values.Lookup(method.Pkg(), method.Name()) == nil {
+			missing = append(missing, method)
+			names = append(names, "`"+method.Name()+"`")
+		}
+	}
+	if len(missing) > 0 {
+		typeName := goTypes.TypeString(structType, goTypes.RelativeTo(pass.Pkg))
+		pass.Reportf(callExpr.Pos(), "`%s` is registered as a struct value, so its methods with pointer receivers (%s) "+
+			"are not registered as activities. Register `&%s{}` instead", typeName, strings.Join(names, ", "), typeName)
+	}
+	return missing
+}
+
+// identifyActivityStruct expands a struct registration into the activities Temporal registers, by name:
+// one per exported method, named after the method (prefixed with RegisterActivityOptions.Name, if set).
+// Methods that are not valid activities fail the registration, unless SkipInvalidStructFunctions is set.
+func identifyActivityStruct(pass *analysis.Pass, callExpr *ast.CallExpr, structType goTypes.Type) map[string]goTypes.Object {
+	var prefix string
+	var skipInvalid bool
+	if len(callExpr.Args) > 1 {
+		prefix, _ = asttools.StringValue(pass.TypesInfo, asttools.FieldValue(callExpr.Args[1], "Name"))
+		skipInvalid, _ = asttools.BoolValue(pass.TypesInfo, asttools.FieldValue(callExpr.Args[1], "SkipInvalidStructFunctions"))
+	}
+	typeName := goTypes.TypeString(structType, goTypes.RelativeTo(pass.Pkg))
+
+	activities := map[string]goTypes.Object{}
+	methods := goTypes.NewMethodSet(structType)
+	for i := range methods.Len() {
+		method := methods.At(i).Obj()
+		if !method.Exported() {
+			continue
+		}
+		sig := method.Type().(*goTypes.Signature)
+		if problem := activityMethodProblem(sig); problem != "" {
+			if !skipInvalid {
+				pass.Reportf(callExpr.Pos(), "Method `%s` of `%s` is not a valid activity (%s), registration will fail. "+
+					"Unexport it, or set SkipInvalidStructFunctions to skip it", method.Name(), typeName, problem)
 			}
-			altObj := goTypes.NewFunc(firstArgObj.Pos(), firstArgObj.Pkg(), field.String(), sign)
-			fmt.Printf("Found an alternative name for activity: %s\n", altObj.Name())
-			return altObj
+			continue
+		}
+		if sig.Params().Len() < 1 || sig.Params().At(0).Type().String() != external.ActivityCtx {
+			pass.Reportf(callExpr.Pos(), "Activity method `%s` of `%s` must take a context.Context as the first argument",
+				method.Name(), typeName)
 		}
+		activities[prefix+method.Name()] = method
 	}
-	return nil
+	if len(activities) == 0 {
+		pass.Reportf(callExpr.Pos(), "No activities (exported methods) found in `%s`, registration will fail", typeName)
+	}
+	return activities
+}
+
+// activityMethodProblem returns why Temporal would refuse to register the method as an activity,
+// or an empty string if it wouldn't.
+// The SDK checks the method with its receiver as the first input, so only the first parameter
+// of the method cannot be a workflow.Context.
+func activityMethodProblem(sig *goTypes.Signature) string {
+	if sig.Params().Len() > 0 && external.WorkflowCtx.MatchString(sig.Params().At(0).Type().String()) {
+		return "takes a workflow.Context"
+	}
+	results := sig.Results()
+	if results.Len() < 1 || results.Len() > 2 {
+		return fmt.Sprintf("returns %d values instead of a result and an error, or just an error", results.Len())
+	}
+	last := results.At(results.Len() - 1).Type()
+	if !goTypes.Implements(last, goTypes.Universe.Lookup("error").Type().Underlying().(*goTypes.Interface)) {
+		return "does not return an error as the last value"
+	}
+	if results.Len() == 2 {
+		if what := unsupportedResult(results.At(0).Type()); what != "" {
+			return fmt.Sprintf("returns %s as the result", what)
+		}
+	}
+	return ""
 }
 
 func checkCalleeMatchesRegistration(pass *analysis.Pass, registration Registration) {
@@ -247,4 +543,50 @@ func checkCalleeMatchesRegistration(pass *analysis.Pass, registration Registrati
 		// should not happen
 		fmt.Fprintf(os.Stderr, "ERROR: Unsupported Temporal.io call type: %v\n", registration.Type)
 	}
+	if registration.Type != types.NotSupported {
+		checkRegisteredResults(pass, registration)
+	}
+}
+
+// checkRegisteredResults checks that the workflow/activity returns either an error, or a result and an error,
+// and that the result is a value, as Temporal.io panics at registration otherwise.
+// The serializability of the result is checked at the declaration, see checkDeclarations.
+func checkRegisteredResults(pass *analysis.Pass, registration Registration) {
+	kind := "Workflow"
+	if registration.Type == types.Activity {
+		kind = "Activity"
+	}
+	results := registration.CalleeSignature.Results()
+	if results.Len() < 1 || results.Len() > 2 {
+		pass.Reportf(registration.Call.Pos(),
+			"%s must return either an error, or a result and an error, but returns %d values", kind, results.Len())
+		return
+	}
+	if last := results.At(results.Len() - 1).Type(); last.String() != "error" {
+		pass.Reportf(registration.Call.Pos(), "%s must return an error as the last value, but returns %s", kind, last)
+		return
+	}
+	if results.Len() == 2 {
+		result := results.At(0).Type()
+		if what := unsupportedResult(result); what != "" {
+			pass.Reportf(registration.Call.Pos(), "%s result (`%s`) must be a serializable value, not %s",
+				kind, result, what)
+		}
+	}
+}
+
+// unsupportedResult returns what the result type is, if Temporal.io rejects it at registration:
+// a channel, a function or an unsafe.Pointer. Returns an empty string otherwise.
+func unsupportedResult(t goTypes.Type) string {
+	switch t := t.Underlying().(type) {
+	case *goTypes.Chan:
+		return "a channel"
+	case *goTypes.Signature:
+		return "a function"
+	case *goTypes.Basic:
+		if t.Kind() == goTypes.UnsafePointer {
+			return "an unsafe.Pointer"
+		}
+	}
+	return ""
 }
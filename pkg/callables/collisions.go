@@ -0,0 +1,105 @@
+package callables
+
+import (
+	"go/ast"
+	goTypes "go/types"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// checkCollisions reports, per worker, registrations that make the worker panic at start, or route calls wrongly:
+// a function registered twice, two functions registered under the same name, and a function registered
+// both as a workflow and as an activity.
+// Registrations with DisableAlreadyRegisteredCheck set are expected to replace the previous ones, and are not reported.
+func checkCollisions(pass *analysis.Pass, registrations []Registration) {
+	type key struct {
+		worker goTypes.Object
+		t      types.TemporalIoCallType
+		name   string
+	}
+	byName := map[key]Registration{}
+	type objKey struct {
+		worker goTypes.Object
+		o      goTypes.Object
+	}
+	byCallee := map[objKey]Registration{}
+
+	for _, r := range registrations {
+		worker := workerOf(pass, r.Call)
+		if worker == nil {
+			continue
+		}
+		if r.Callee != nil {
+			if previous, ok := byCallee[objKey{worker, r.Callee}]; ok && previous.Type != r.Type {
+				pass.Reportf(r.Call.Pos(), "`%s` is registered both as a workflow and as an activity on `%s` (at %s)",
+					r.Callee.Name(), worker.Name(), pass.Fset.Position(previous.Call.Pos()))
+			} else if !ok {
+				byCallee[objKey{worker, r.Callee}] = r
+			}
+		}
+		if r.Name == "" {
+			continue
+		}
+		k := key{worker, r.Type, r.Name}
+		previous, ok := byName[k]
+		byName[k] = r
+		if !ok || alreadyRegisteredCheckDisabled(pass, r.Call) {
+			continue
+		}
+		kind := "Workflow"
+		if r.Type == types.Activity {
+			kind = "Activity"
+		}
+		position := pass.Fset.Position(previous.Call.Pos())
+		if previous.Callee == r.Callee {
+			pass.Reportf(r.Call.Pos(), "%s `%s` is already registered on `%s` (at %s), registration will panic. "+
+				"Set DisableAlreadyRegisteredCheck if it's intended", kind, r.Name, worker.Name(), position)
+			continue
+		}
+		pass.Reportf(r.Call.Pos(), "%s name `%s` of `%s` collides with `%s` registered on `%s` (at %s), registration will panic. "+
+			"Register one of them with a different Name", kind, r.Name, qualifiedName(pass, r.Callee),
+			qualifiedName(pass, previous.Callee), worker.Name(), position)
+	}
+}
+
+// workerOf returns the variable (or field) holding the worker the registration call is made on.
+func workerOf(pass *analysis.Pass, call ast.CallExpr) goTypes.Object {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	return pass.TypesInfo.ObjectOf(asttools.IdentifierOf(selector.X))
+}
+
+// alreadyRegisteredCheckDisabled returns true if the registration options set DisableAlreadyRegisteredCheck.
+func alreadyRegisteredCheckDisabled(pass *analysis.Pass, call ast.CallExpr) bool {
+	if len(call.Args) < 2 {
+		return false
+	}
+	disabled, _ := asttools.BoolValue(pass.TypesInfo, asttools.FieldValue(call.Args[1], "DisableAlreadyRegisteredCheck"))
+	return disabled
+}
+
+// qualifiedName returns the name of the function, qualified by its package (or receiver type, for methods)
+// when it's not declared in this package.
+func qualifiedName(pass *analysis.Pass, o goTypes.Object) string {
+	if o == nil {
+		return "an unresolved function"
+	}
+	if fn, ok := o.(*goTypes.Func); ok {
+		if recv := fn.Signature().Recv(); recv != nil {
+			recvName := goTypes.TypeString(recv.Type(), goTypes.RelativeTo(pass.Pkg))
+			if _, isPtr := recv.Type().(*goTypes.Pointer); isPtr {
+				recvName = "(" + recvName + ")"
+			}
+			return recvName + "." + fn.Name()
+		}
+	}
+	if o.Pkg() != nil && o.Pkg() != pass.Pkg {
+		return o.Pkg().Name() + "." + o.Name()
+	}
+	return o.Name()
+}
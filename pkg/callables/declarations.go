@@ -0,0 +1,78 @@
+package callables
+
+import (
+	"go/token"
+	goTypes "go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// checkDeclarations checks that every registered workflow and activity (including the methods of registered structs)
+// takes serializable parameters and returns a serializable result, reported once per function, at its declaration.
+// This covers the workflows started from other services, the CLI or schedules, which we never see the calls of.
+// Functions declared in other packages are reported at their registration instead.
+func checkDeclarations(pass *analysis.Pass, registrations []Registration) {
+	checked := map[goTypes.Object]bool{}
+	for _, r := range registrations {
+		fn, ok := r.Callee.(*goTypes.Func)
+		if !ok || checked[fn] || r.Type == types.NotSupported {
+			continue
+		}
+		checked[fn] = true
+
+		kind := "Workflow"
+		if r.Type == types.Activity {
+			kind = "Activity"
+		}
+		pos := fn.Pos()
+		if fn.Pkg() != pass.Pkg {
+			pos = r.Call.Pos()
+		}
+		checkParams(pass, kind, fn, pos)
+
+		results := fn.Signature().Results()
+		if results.Len() != 2 {
+			// no result, or an invalid signature reported at the registration
+			continue
+		}
+		result := results.At(0).Type()
+		if unsupportedResult(result) != "" {
+			continue
+		}
+		if is, why := asttools.IsSerializable(result); !is {
+			pass.Reportf(pos, "%s `%s` returns a result (`%s`) that is not serializable\n\treason: %s",
+				kind, fn.Name(), goTypes.TypeString(result, goTypes.RelativeTo(pass.Pkg)), why)
+		}
+	}
+}
+
+// checkParams reports the parameters of the workflow/activity that are not serializable, after the context.
+func checkParams(pass *analysis.Pass, kind string, fn *goTypes.Func, pos token.Pos) {
+	signature := fn.Signature()
+	params := signature.Params()
+	for i := range params.Len() {
+		param := params.At(i)
+		t := param.Type()
+		if i == 0 && (external.WorkflowCtx.MatchString(t.String()) || t.String() == external.ActivityCtx) {
+			continue
+		}
+		if signature.Variadic() && i == params.Len()-1 {
+			t = t.(*goTypes.Slice).Elem()
+		}
+		if is, why := asttools.IsSerializable(t); !is {
+			paramPos := pos
+			if fn.Pkg() == pass.Pkg {
+				paramPos = param.Pos()
+			}
+			pass.Reportf(paramPos, "Parameter `%s` (`%s`) of %s `%s` is not serializable - it will not be visible "+
+				"to the %s, and will assume its zero value\n\treason: %s",
+				param.Name(), goTypes.TypeString(t, goTypes.RelativeTo(pass.Pkg)), strings.ToLower(kind), fn.Name(),
+				strings.ToLower(kind), why)
+		}
+	}
+}
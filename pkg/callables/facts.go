@@ -1,16 +1,50 @@
 package callables
 
 import (
-	"go/types"
+	goTypes "go/types"
+
+	"golang.org/x/tools/go/types/objectpath"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
 )
 
-type isWorkflow struct{}
+// isWorkflow marks a function registered as a workflow, under the given names.
+type isWorkflow struct {
+	Names []string
+}
+
+func (i *isWorkflow) AFact() {}
+
+// isActivity marks a function (or method) registered as an activity, under the given names.
+type isActivity struct {
+	Names []string
+}
+
+func (i *isActivity) AFact() {}
 
-func (i isWorkflow) AFact() {}
+// registrationsFact records all the workflows and activities registered in a package,
+// including the ones declared in other packages (which can't carry object facts from here),
+// so that packages importing it can resolve them by name.
+type registrationsFact struct {
+	Registrations []registered
+	// PartialQueues are the task queues whose workers are passed to other functions,
+	// which may register more workflows and activities on them
+	PartialQueues []string
+}
 
-type isActivity struct{}
+func (r *registrationsFact) AFact() {}
 
-func (i isActivity) AFact() {}
+// registered is a workflow or an activity registered under Name, declared in the package Pkg.
+// The function is identified by its object path, and its signature is included for readability.
+// TaskQueue is the task queue of the worker it's registered on, empty for the registrations on any worker.
+type registered struct {
+	Type      types.TemporalIoCallType
+	Name      string
+	Pkg       string
+	Path      objectpath.Path
+	Signature string
+	TaskQueue string
+}
 
 type isWorkflowCall struct{}
 
@@ -21,6 +55,98 @@ type isActivityCall struct{}
 func (i isActivityCall) AFact() {}
 
 type Callables struct {
-	Workflows  []types.Object
-	Activities []types.Object
+	// Workflows and Activities are the ones registered in this package
+	Workflows  []goTypes.Object
+	Activities []goTypes.Object
+	// Registrations are all the registrations in this package, in order
+	Registrations []Registration
+	// Registry resolves workflows and activities by the names they are registered under,
+	// in this package and the packages it imports.
+	Registry Registry
+	// OwnsWorkers is true if the package creates workers, and does not pass them to other functions:
+	// all the workflows and activities registered on its workers are known, not only some of them.
+	OwnsWorkers bool
+	// UnregisteredMethods are the pointer-receiver methods of structs registered by value,
+	// which are not registered as activities, mapped to the name of the struct type.
+	UnregisteredMethods map[goTypes.Object]string
+}
+
+// Registry maps the names Temporal registers workflows and activities under
+// (function or method names, or the Name from the registration options) to the registered functions.
+type Registry struct {
+	Workflows  map[string]goTypes.Object
+	Activities map[string]goTypes.Object
+	// registered has all the registered functions, including the ones whose names collide
+	registered map[types.TemporalIoCallType]map[goTypes.Object]bool
+	// queues has the registrations on the workers of each task queue, when the task queue is known
+	queues map[string]Registry
+	// partialQueues are the task queues whose workers may have registrations we cannot attribute to them
+	partialQueues map[string]bool
+}
+
+func newRegistry() Registry {
+	return Registry{
+		Workflows:  map[string]goTypes.Object{},
+		Activities: map[string]goTypes.Object{},
+		registered: map[types.TemporalIoCallType]map[goTypes.Object]bool{
+			types.Workflow: {},
+			types.Activity: {},
+		},
+		queues:        map[string]Registry{},
+		partialQueues: map[string]bool{},
+	}
+}
+
+// add registers the workflow/activity under the name (if known).
+func (r Registry) add(t types.TemporalIoCallType, name string, o goTypes.Object) {
+	switch t {
+	case types.Workflow:
+		if name != "" {
+			r.Workflows[name] = o
+		}
+	case types.Activity:
+		if name != "" {
+			r.Activities[name] = o
+		}
+	default:
+		return
+	}
+	r.registered[t][o] = true
+}
+
+// addOnQueue registers the workflow/activity on the workers of the task queue.
+func (r Registry) addOnQueue(queue string, t types.TemporalIoCallType, name string, o goTypes.Object) {
+	q, ok := r.queues[queue]
+	if !ok {
+		q = newRegistry()
+		r.queues[queue] = q
+	}
+	q.add(t, name, o)
+}
+
+// TaskQueue returns the registrations on the workers of the task queue, and false if there are no known workers,
+// or if they may register workflows and activities that cannot be seen (e.g. in a function they are passed to).
+func (r Registry) TaskQueue(queue string) (Registry, bool) {
+	if r.partialQueues[queue] {
+		return Registry{}, false
+	}
+	q, ok := r.queues[queue]
+	return q, ok
+}
+
+// IsRegistered returns true if the function (or method) is registered as a workflow/activity.
+func (r Registry) IsRegistered(t types.TemporalIoCallType, o goTypes.Object) bool {
+	return r.registered[t][o]
+}
+
+// Lookup returns the workflow or activity registered under the name, or nil if there's none.
+func (r Registry) Lookup(t types.TemporalIoCallType, name string) goTypes.Object {
+	switch t {
+	case types.Workflow:
+		return r.Workflows[name]
+	case types.Activity:
+		return r.Activities[name]
+	default:
+		return nil
+	}
 }
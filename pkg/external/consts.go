@@ -6,6 +6,7 @@ const (
 	WorkerType  = "go.temporal.io/sdk/worker.Worker"
 	ClientType  = "go.temporal.io/sdk/client.Client"
 	WorkflowPkg = "go.temporal.io/sdk/workflow"
+	WorkerPkg   = "go.temporal.io/sdk/worker"
 
 	WorkflowCtxRe = "go\\.temporal\\.io/sdk/(workflow|internal)\\.Context"
 	ActivityCtx   = "context.Context"
@@ -13,9 +14,47 @@ const (
 	RegisterActivity            = "RegisterActivity"
 	RegisterActivityWithOptions = "RegisterActivityWithOptions"
 	RegisterWorkflow            = "RegisterWorkflow"
+	RegisterWorkflowWithOptions = "RegisterWorkflowWithOptions"
 
 	ExecuteActivity = "ExecuteActivity"
 	ExecuteWorkflow = "ExecuteWorkflow"
+
+	ExecuteChildWorkflow       = "ExecuteChildWorkflow"
+	NewWorker                  = "New"
+	WithActivityOptions        = "WithActivityOptions"
+	WithChildOptions           = "WithChildOptions"
+	ScheduleWorkflowActionType = "go.temporal.io/sdk/internal.ScheduleWorkflowAction"
+
+	NewContinueAsNewError            = "NewContinueAsNewError"
+	NewContinueAsNewErrorWithOptions = "NewContinueAsNewErrorWithOptions"
+
+	ReceiveChannelType      = "go.temporal.io/sdk/internal.ReceiveChannel"
+	ChildWorkflowFutureType = "go.temporal.io/sdk/internal.ChildWorkflowFuture"
+
+	GetSignalChannel            = "GetSignalChannel"
+	GetSignalChannelWithOptions = "GetSignalChannelWithOptions"
+	SignalWorkflow              = "SignalWorkflow"
+	SignalWithStartWorkflow     = "SignalWithStartWorkflow"
+	SignalExternalWorkflow      = "SignalExternalWorkflow"
+	SignalChildWorkflow         = "SignalChildWorkflow"
+
+	EncodedValueType = "go.temporal.io/sdk/converter.EncodedValue"
+
+	SetQueryHandler            = "SetQueryHandler"
+	SetQueryHandlerWithOptions = "SetQueryHandlerWithOptions"
+	QueryWorkflow              = "QueryWorkflow"
+	Get                        = "Get"
+
+	UpdateHandlerOptionsType  = "go.temporal.io/sdk/internal.UpdateHandlerOptions"
+	UpdateWorkflowOptionsType = "go.temporal.io/sdk/internal.UpdateWorkflowOptions"
+
+	SetUpdateHandler            = "SetUpdateHandler"
+	SetUpdateHandlerWithOptions = "SetUpdateHandlerWithOptions"
+
+	Receive                  = "Receive"
+	ReceiveAsync             = "ReceiveAsync"
+	ReceiveAsyncWithMoreFlag = "ReceiveAsyncWithMoreFlag"
+	ReceiveWithTimeout       = "ReceiveWithTimeout"
 )
 
 var WorkflowCtx = regexp.MustCompile(WorkflowCtxRe)
@@ -0,0 +1,46 @@
+package asttools
+
+import (
+	"go/types"
+	"sync/atomic"
+)
+
+// strictPointerMatch requires pointer types to match exactly, see SetStrictPointerMatch.
+var strictPointerMatch atomic.Bool
+
+// SetStrictPointerMatch makes TypesMatch require pointers to match exactly. By default, a pointer and the type
+// it points to match, as payloads are serialized: Temporal.io decodes either into the other.
+func SetStrictPointerMatch(strict bool) {
+	strictPointerMatch.Store(strict)
+}
+
+// TypesMatch returns true if a value of the actual type, once serialized, is decoded into the expected type:
+// the arguments of workflows, activities and handlers, the signal payloads, and the results.
+// Unknown types (nil) match anything, and so do interfaces, which can hold any value:
+// expected interfaces only need to be implemented by the actual type.
+func TypesMatch(expected, actual types.Type) bool {
+	if expected == nil || actual == nil || types.Identical(expected, actual) {
+		return true
+	}
+	if types.IsInterface(actual) {
+		return true
+	}
+	if types.IsInterface(expected) {
+		return types.AssignableTo(actual, expected)
+	}
+	if strictPointerMatch.Load() {
+		return false
+	}
+	// a pointer vs non-pointer mismatch (Temporal.io handles these)
+	if ptr, ok := expected.(*types.Pointer); ok && types.Identical(ptr.Elem(), actual) {
+		return true
+	}
+	if ptr, ok := actual.(*types.Pointer); ok && types.Identical(ptr.Elem(), expected) {
+		return true
+	}
+	// a nil pointer to a struct can be untyped
+	if _, ok := expected.Underlying().(*types.Struct); ok && actual == types.Typ[types.UntypedNil] {
+		return true
+	}
+	return false
+}
@@ -3,8 +3,11 @@ package asttools
 import (
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/types"
-	"reflect"
+	"slices"
+
+	"golang.org/x/tools/go/types/typeutil"
 )
 
 // IdentifierOf returns the *ast.Ident for the given *ast.Expr, whatever it is.
@@ -32,76 +35,186 @@ func IdentifierOf(e ast.Expr) *ast.Ident {
 	}
 }
 
-// IsSerializable returns true if the given type is serializable to JSON.
-// This is a very rough approximation, but it's good enough for our purposes.
-// Returns if the type is serializable, and if not, why.
-func IsSerializable(t types.Type) (bool, string) {
-	// if the type has a custom Marshaler, it means the author of the type
-	// knows how to serialize it, so we assume it's serializable
+// StringValue returns the value of a constant string expression (a literal, or a named constant),
+// and whether the expression was one.
+func StringValue(info *types.Info, e ast.Expr) (string, bool) {
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// BoolValue returns the value of a constant boolean expression, and whether the expression was one.
+func BoolValue(info *types.Info, e ast.Expr) (bool, bool) {
+	tv, ok := info.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Bool {
+		return false, false
+	}
+	return constant.BoolVal(tv.Value), true
+}
+
+// IsFuncCall returns true if the call invokes one of the named package-level functions of pkgPath,
+// e.g. IsFuncCall(info, call, "go.temporal.io/sdk/workflow", "GetSignalChannel").
+func IsFuncCall(info *types.Info, call *ast.CallExpr, pkgPath string, names ...string) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return false
+	}
+	if fn.Type().(*types.Signature).Recv() != nil {
+		return false
+	}
+	return slices.Contains(names, fn.Name())
+}
+
+// IsMethodCall returns true if the call invokes one of the named methods on a receiver of type recvType,
+// e.g. IsMethodCall(info, call, "go.temporal.io/sdk/client.Client", "SignalWorkflow").
+func IsMethodCall(info *types.Info, call *ast.CallExpr, recvType string, names ...string) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !slices.Contains(names, selector.Sel.Name) {
+		return false
+	}
+	xType := info.TypeOf(selector.X)
+	if xType == nil {
+		return false
+	}
+	if ptr, ok := xType.(*types.Pointer); ok {
+		xType = ptr.Elem()
+	}
+	// SDK types are mostly aliases of go.temporal.io/sdk/internal types, compare with what they stand for
+	return types.Unalias(xType).String() == recvType
+}
 
-	// we cannot check if it implements Marshaler directly. It's from the `types` package,
-	// and not from reflect (it defines a type at compile time, not at runtime),
-	// so we have to check if it's a named type, and if it has a method called MarshalJSON
-	if named, ok := t.(*types.Named); ok {
-		for i := 0; i < named.NumMethods(); i++ {
-			m := named.Method(i)
-			if m.Name() == "MarshalJSON" {
-				return true, "implements MarshalJSON"
+// FieldValue returns the expression a struct literal (or a pointer to one) sets the named field to,
+// or nil if the expression is not a struct literal, or does not set the field by name.
+func FieldValue(e ast.Expr, field string) ast.Expr {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return FieldValue(e.X, field)
+	case *ast.UnaryExpr:
+		return FieldValue(e.X, field)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
 			}
-			if m.Name() == "ProtoMessage" {
-				return true, "is a protobuf message"
+			if key, ok := kv.Key.(*ast.Ident); ok && key.Name == field {
+				return kv.Value
 			}
 		}
-	} else if named, ok := t.Underlying().(*types.Named); ok {
-		return IsSerializable(named)
 	}
+	return nil
+}
 
-	switch t := t.(type) {
-	case *types.Struct:
-		for i := 0; i < t.NumFields(); i++ {
-			f := t.Field(i)
-			if is, why := IsSerializable(f.Type()); !is {
-				return false, fmt.Sprintf("field %s (%s) is not serializable,\n\treason: %s", f.Name(), f.Type().String(), why)
-			}
-			// check if field serializes to json:
-			// get json tag, it can be something like `json:"name,omitempty"`
-			t := reflect.StructTag(t.Tag(i))
-			jsonTag := t.Get("json")
-			switch jsonTag {
-			case "-":
-				return false, fmt.Sprintf("field %s is not serializable,\n\treason: field is marked with json:\"-\"", f.Name())
-			case "":
-				if ast.IsExported(f.Name()) {
-					if is, why := IsSerializable(f.Type()); !is {
-						return false, fmt.Sprintf("field %s (%s) is not serializable,\n\treason: %s", f.Name(), f.Type().String(), why)
+// Values records the expressions assigned to variables in a package, to follow a variable to the value it holds,
+// e.g. ctx = workflow.WithActivityOptions(ctx, options).
+type Values struct {
+	info     *types.Info
+	assigned map[types.Object][]ast.Expr
+}
+
+// AssignedValues collects the values assigned to variables (by declarations and assignments) in the files.
+// The first variable assigned from a call returning several values, e.g. run, err := c.ExecuteWorkflow(...),
+// holds the call.
+func AssignedValues(info *types.Info, files []*ast.File) Values {
+	v := Values{info: info, assigned: map[types.Object][]ast.Expr{}}
+	assign := func(ident *ast.Ident, value ast.Expr) {
+		if o := info.ObjectOf(ident); o != nil {
+			v.assigned[o] = append(v.assigned[o], value)
+		}
+	}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Rhs) == 1 && len(n.Lhs) > 1 {
+					// x, err := f(): x holds the first result of the call
+					if ident, ok := n.Lhs[0].(*ast.Ident); ok {
+						if _, ok := n.Rhs[0].(*ast.CallExpr); ok {
+							assign(ident, n.Rhs[0])
+						}
+					}
+					return true
+				}
+				if len(n.Lhs) != len(n.Rhs) {
+					return true
+				}
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						assign(ident, n.Rhs[i])
 					}
-					return true, ""
 				}
-				return false, fmt.Sprintf("field %s is not serializable,\n\treason: field is not exported", f.Name())
+			case *ast.ValueSpec:
+				if len(n.Names) != len(n.Values) {
+					return true
+				}
+				for i, name := range n.Names {
+					assign(name, n.Values[i])
+				}
+			}
+			return true
+		})
+	}
+	return v
+}
+
+// At returns the value e holds: if e is a variable, the last value assigned to it before e
+// (followed through other variables), otherwise e itself.
+// Control flow is not taken into account, so it's only an approximation for straight-line code.
+func (v Values) At(e ast.Expr) ast.Expr {
+	// a bound on the number of variables to follow, so that x = y; y = x cannot loop
+	for range 10 {
+		ident, ok := ast.Unparen(e).(*ast.Ident)
+		if !ok {
+			return e
+		}
+		var value ast.Expr
+		for _, a := range v.assigned[v.info.ObjectOf(ident)] {
+			if a.Pos() < ident.Pos() && (value == nil || a.Pos() > value.Pos()) {
+				value = a
 			}
 		}
-		return true, ""
-	case *types.Pointer:
-		return IsSerializable(t.Elem())
-	case *types.Named:
-		return IsSerializable(t.Underlying())
-	case *types.Basic:
-		return true, ""
-	case *types.Slice:
-		return IsSerializable(t.Elem())
-	case *types.Array:
-		return IsSerializable(t.Elem())
-	case *types.Map:
-		if is, why := IsSerializable(t.Key()); !is {
-			// ideally we should check if map key is a basic type, probably
-			return false, fmt.Sprintf("map key (%s) is not serializable,\n\treason: %s", t.Key().String(), why)
+		if value == nil {
+			return e
+		}
+		e = value
+	}
+	return e
+}
+
+// ImportedPackages returns all the packages imported by pkg, directly or not, by path.
+func ImportedPackages(pkg *types.Package) map[string]*types.Package {
+	packages := map[string]*types.Package{}
+	var collect func(pkgs []*types.Package)
+	collect = func(pkgs []*types.Package) {
+		for _, p := range pkgs {
+			if _, seen := packages[p.Path()]; !seen {
+				packages[p.Path()] = p
+				collect(p.Imports())
+			}
 		}
-		return IsSerializable(t.Elem())
-	case *types.Interface:
-		// if it's an interface, we can't know what it is, so make an optimistic assumption
-		return true, ""
+	}
+	collect(pkg.Imports())
+	return packages
+}
+
+// NumberToOrdinal returns the English ordinal of n, e.g. 1st, 2nd, 11th.
+func NumberToOrdinal(n int) string {
+	if n <= 0 {
+		return "0"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		return fmt.Sprintf("%dth", n)
+	}
+	switch n % 10 {
+	case 1:
+		return fmt.Sprintf("%dst", n)
+	case 2:
+		return fmt.Sprintf("%dnd", n)
+	case 3:
+		return fmt.Sprintf("%drd", n)
 	default:
-		// if we don't know what it is, assume it's not serializable
-		return false, fmt.Sprintf("type %s is not serializable (most likely)", t.String())
+		return fmt.Sprintf("%dth", n)
 	}
 }
@@ -1,7 +1,12 @@
 package asttools
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"go/types"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -43,6 +48,19 @@ func TestIsSerializable(t *testing.T) {
 		),
 		false,
 	)))
+	// and can be decoded back
+	timeType.AddMethod(types.NewFunc(0, nil, "UnmarshalJSON", types.NewSignatureType(
+		types.NewVar(0, nil, "t", types.NewPointer(timeType)),
+		nil,
+		nil,
+		types.NewTuple(
+			types.NewVar(0, nil, "", types.NewSlice(types.Typ[types.Byte])),
+		),
+		types.NewTuple(
+			types.NewVar(0, nil, "", errorType),
+		),
+		false,
+	)))
 
 	shouldBeTrue := []types.Type{
 		types.Typ[types.String],
@@ -71,6 +89,20 @@ func TestIsSerializable(t *testing.T) {
 	}
 	shouldBeFalse := []types.Type{
 		types.NewChan(types.SendRecv, types.Typ[types.Int]),
+		types.Typ[types.Complex64],
+		types.Typ[types.Complex128],
+		types.Typ[types.UnsafePointer],
+		types.NewSignatureType(nil, nil, nil, nil, nil, false),
+		types.NewSlice(types.Typ[types.Complex128]),
+		types.NewMap(types.Typ[types.String], types.NewSignatureType(nil, nil, nil, nil, nil, false)),
+		// a channel in a nested struct, after a serializable field
+		types.NewStruct([]*types.Var{
+			types.NewField(0, nil, "A", types.Typ[types.Int], false),
+			types.NewField(0, nil, "Nested", types.NewStruct([]*types.Var{
+				types.NewField(0, nil, "B", types.Typ[types.String], false),
+				types.NewField(0, nil, "C", types.NewChan(types.SendRecv, types.Typ[types.Int]), false),
+			}, nil), false),
+		}, nil),
 	}
 	for _, typ := range shouldBeFalse {
 		t.Run("non-serializable types: "+typ.String(), func(t *testing.T) {
@@ -80,3 +112,593 @@ func TestIsSerializable(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSerializableReasons(t *testing.T) {
+	nested := types.NewStruct([]*types.Var{
+		types.NewField(0, nil, "A", types.Typ[types.Int], false),
+		types.NewField(0, nil, "Nested", types.NewStruct([]*types.Var{
+			types.NewField(0, nil, "OnDone", types.NewSignatureType(nil, nil, nil, nil, nil, false), false),
+		}, nil), false),
+	}, nil)
+	expected := map[types.Type][]string{
+		types.Typ[types.Complex128]:    {"complex"},
+		types.Typ[types.UnsafePointer]: {"unsafe.Pointer"},
+		nested:                         {"Nested", "OnDone", "function"},
+	}
+	for typ, words := range expected {
+		_, why := IsSerializable(typ)
+		for _, word := range words {
+			if !strings.Contains(why, word) {
+				t.Errorf("expected the reason %s is not serializable to mention %q, got %q", typ, word, why)
+			}
+		}
+	}
+}
+
+func TestStringValue(t *testing.T) {
+	src := `package p
+
+const approve = "approve"
+
+var (
+	a = "literal"
+	b = approve
+	c = approve + "d"
+	d = a
+	e = 42
+)
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Types: map[ast.Expr]types.TypeAndValue{}}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]struct {
+		value string
+		ok    bool
+	}{
+		"a": {"literal", true},
+		"b": {"approve", true},
+		"c": {"approved", true},
+		"d": {"", false},
+		"e": {"", false},
+	}
+	for _, spec := range f.Decls[1].(*ast.GenDecl).Specs {
+		spec := spec.(*ast.ValueSpec)
+		name := spec.Names[0].Name
+		t.Run(name, func(t *testing.T) {
+			value, ok := StringValue(info, spec.Values[0])
+			if value != expected[name].value || ok != expected[name].ok {
+				t.Errorf("expected (%q, %v), got (%q, %v)", expected[name].value, expected[name].ok, value, ok)
+			}
+		})
+	}
+}
+
+func TestValuesAt(t *testing.T) {
+	src := `package p
+
+func f(s string) string { return s }
+
+func h() (string, error) { return "", nil }
+
+func g() {
+	a := "first"
+	b := a
+	a = f(a)
+	c := a
+	var d = c
+	e, err := h()
+	_, _, _, _ = b, d, e, err
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	if _, err := new(types.Config).Check("p", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+	values := AssignedValues(info, []*ast.File{f})
+
+	// the last statement uses b (holding "first"), d (holding f(a)), e (holding h()) and err (unknown)
+	body := f.Decls[2].(*ast.FuncDecl).Body.List
+	last := body[len(body)-1].(*ast.AssignStmt)
+	if lit, ok := values.At(last.Rhs[0]).(*ast.BasicLit); !ok || lit.Value != `"first"` {
+		t.Errorf("expected b to hold \"first\", got %#v", values.At(last.Rhs[0]))
+	}
+	if _, ok := values.At(last.Rhs[1]).(*ast.CallExpr); !ok {
+		t.Errorf("expected d to hold f(a), got %#v", values.At(last.Rhs[1]))
+	}
+	if _, ok := values.At(last.Rhs[2]).(*ast.CallExpr); !ok {
+		t.Errorf("expected e to hold h(), got %#v", values.At(last.Rhs[2]))
+	}
+	if _, ok := values.At(last.Rhs[3]).(*ast.Ident); !ok {
+		t.Errorf("expected err not to be followed, got %#v", values.At(last.Rhs[3]))
+	}
+}
+
+func TestIsSerializableRecursive(t *testing.T) {
+	src := `package p
+
+type Node struct {
+	Name     string
+	Children []*Node
+	Parent   *Node
+}
+
+type Tree map[string]Tree
+
+// A refers to B, and B back to A, which is not serializable
+type A struct {
+	B    *B
+	Done chan bool
+}
+
+type B struct {
+	A *A
+}
+`
+	pkg := typeCheck(t, src)
+	expected := map[string]bool{
+		"Node": true,
+		"Tree": true,
+		"A":    false,
+		// checked after A, while its result assumed A to be serializable
+		"B": false,
+	}
+	for _, name := range []string{"Node", "Tree", "A", "B"} {
+		t.Run(name, func(t *testing.T) {
+			if is, why := IsSerializable(pkg.Scope().Lookup(name).Type()); is != expected[name] {
+				t.Errorf("expected %s serializable: %v, got %v (%s)", name, expected[name], is, why)
+			}
+		})
+	}
+}
+
+func typeCheck(t *testing.T, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+// resetSerializableTypes forgets the types added with AddSerializableTypes.
+func resetSerializableTypes() {
+	payloadConfig.Lock()
+	payloadConfig.serializable = map[string]bool{}
+	payloadConfig.Unlock()
+	resetSerializableCache()
+}
+
+// serializableCase is the expected result of IsSerializable for a type: whether it's serializable,
+// and the words the reason mentions.
+type serializableCase struct {
+	serializable bool
+	mentions     []string
+}
+
+// checkSerializable checks IsSerializable on the types of src, by name.
+func checkSerializable(t *testing.T, src string, cases map[string]serializableCase) {
+	t.Helper()
+	pkg := typeCheck(t, src)
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			is, why := IsSerializable(pkg.Scope().Lookup(name).Type())
+			if is != c.serializable {
+				t.Errorf("expected %s serializable: %v, got %v (%s)", name, c.serializable, is, why)
+			}
+			for _, word := range c.mentions {
+				if !strings.Contains(why, word) {
+					t.Errorf("expected the reason to mention %q, got %q", word, why)
+				}
+			}
+		})
+	}
+}
+
+func TestIsSerializableJSONFields(t *testing.T) {
+	src := `package p
+
+type Skipped struct {
+	Name string
+	Done chan bool ` + "`json:\"-\"`" + `
+}
+
+type NamedDash struct {
+	Done chan bool ` + "`json:\"-,\"`" + `
+}
+
+type TaggedUnexported struct {
+	A string
+	b string ` + "`json:\"b,omitempty\"`" + `
+}
+
+type ManyUnexported struct {
+	A string
+	b int
+	c int
+}
+
+type inner struct {
+	X string
+}
+
+type Embedding struct {
+	inner
+	Y int
+}
+`
+	checkSerializable(t, src, map[string]serializableCase{
+		"Skipped":          {true, nil},
+		"NamedDash":        {false, []string{"Done", "channel"}},
+		"TaggedUnexported": {false, []string{"field b", "no effect"}},
+		"ManyUnexported":   {false, []string{"field b", "field c"}},
+		"Embedding":        {true, nil},
+	})
+}
+
+func TestIsSerializableEmbedded(t *testing.T) {
+	src := `package p
+
+type Audit struct {
+	ID        string
+	CreatedBy string
+}
+
+type Order struct {
+	ID    string
+	Total int
+}
+
+// both embedded structs have an ID at the same depth: encoding/json drops both
+type Colliding struct {
+	Audit
+	Order
+}
+
+// the ID of the embedded Audit is hidden by the one of the struct
+type Hiding struct {
+	Audit
+	ID string
+}
+
+type Named struct {
+	ID string ` + "`json:\"ID\"`" + `
+}
+
+// the tagged field wins over the untagged one at the same depth
+type Tagged struct {
+	Audit
+	*Named
+}
+
+type secret struct {
+	Value string
+}
+
+type EmbeddedUnexportedPointer struct {
+	*secret
+}
+
+type EmbeddedUnexportedTagged struct {
+	secret ` + "`json:\"secret\"`" + `
+}
+`
+	checkSerializable(t, src, map[string]serializableCase{
+		"Colliding":                 {false, []string{"Audit.ID", "Order.ID", "collides"}},
+		"Hiding":                    {false, []string{"Audit.ID", "hidden by ID"}},
+		"Tagged":                    {false, []string{"Audit.ID", "hidden by Named.ID"}},
+		"EmbeddedUnexportedPointer": {false, []string{"secret", "cannot decode"}},
+		"EmbeddedUnexportedTagged":  {true, nil},
+	})
+}
+
+func TestIsSerializableMarshalers(t *testing.T) {
+	src := `package p
+
+type RoundTrip struct{ v int }
+
+func (r RoundTrip) MarshalJSON() ([]byte, error) { return nil, nil }
+func (r *RoundTrip) UnmarshalJSON([]byte) error  { return nil }
+
+type EncodeOnly struct{ v int }
+
+func (e EncodeOnly) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type ValueReceiver struct{ v int }
+
+func (v ValueReceiver) MarshalJSON() ([]byte, error) { return nil, nil }
+func (v ValueReceiver) UnmarshalJSON([]byte) error  { return nil }
+
+type Text struct{ v int }
+
+func (t Text) MarshalText() ([]byte, error) { return nil, nil }
+func (t *Text) UnmarshalText([]byte) error  { return nil }
+
+// a string key only encoded with MarshalText: still a string key
+type Label string
+
+func (l Label) MarshalText() ([]byte, error) { return nil, nil }
+
+type Point struct{ X, Y int }
+
+type ByText map[Text]int
+type ByStruct map[Point]int
+type ByInt map[int64]string
+type ByLabel map[Label]int
+`
+	checkSerializable(t, src, map[string]serializableCase{
+		"RoundTrip":     {true, nil},
+		"EncodeOnly":    {false, []string{"does not implement UnmarshalJSON"}},
+		"ValueReceiver": {false, []string{"value receiver"}},
+		"Text":          {true, nil},
+		"ByText":        {true, nil},
+		"ByStruct":      {false, []string{"map keys must be"}},
+		"ByInt":         {true, nil},
+		"ByLabel":       {true, nil},
+	})
+}
+
+func TestIsSerializablePayloadConverters(t *testing.T) {
+	src := `package p
+
+type Event struct {
+	Name string
+	Done chan bool
+}
+
+type Opaque struct {
+	state []byte
+}
+
+type Payload interface {
+	Kind() string
+}
+
+type Custom struct {
+	kind string
+}
+
+func (c Custom) Kind() string { return c.kind }
+`
+	pkg := typeCheck(t, src)
+	lookup := func(name string) types.Type { return pkg.Scope().Lookup(name).Type() }
+	t.Cleanup(func() {
+		_ = SetPayloadConverters(DefaultPayloadConverters)
+		resetSerializableTypes()
+	})
+
+	if is, _ := IsSerializable(lookup("Event")); is {
+		t.Errorf("expected Event not to be serializable to JSON")
+	}
+	if err := SetPayloadConverters([]string{"nil", "[]byte", "gob"}); err != nil {
+		t.Fatal(err)
+	}
+	// gob skips the fields of channel types
+	if is, why := IsSerializable(lookup("Event")); !is {
+		t.Errorf("expected Event to be serializable with gob, wasn't: %s", why)
+	}
+
+	if err := SetPayloadConverters([]string{"nil", "[]byte"}); err != nil {
+		t.Fatal(err)
+	}
+	if is, why := IsSerializable(lookup("Event")); is || !strings.Contains(why, "no payload converter") {
+		t.Errorf("expected no converter to accept Event, got %v (%s)", is, why)
+	}
+	if is, why := IsSerializable(types.NewSlice(types.Typ[types.Byte])); !is {
+		t.Errorf("expected []byte to be serializable, wasn't: %s", why)
+	}
+	if err := SetPayloadConverters([]string{"yaml"}); err == nil {
+		t.Errorf("expected an unknown converter to be rejected")
+	}
+
+	_ = SetPayloadConverters(DefaultPayloadConverters)
+	AddSerializableTypes([]string{"p.Opaque", "p.Payload"})
+	for _, name := range []string{"Opaque", "Custom"} {
+		if is, why := IsSerializable(lookup(name)); !is {
+			t.Errorf("expected %s to be configured as serializable, wasn't: %s", name, why)
+		}
+	}
+}
+
+func TestIsSerializableStrictInterfaces(t *testing.T) {
+	src := `package p
+
+type Shape interface {
+	Area() float64
+}
+
+type Line struct {
+	Meta map[string]any
+}
+
+type Order struct {
+	Lines  []Line
+	Shape  Shape
+	Err    error
+	Other  Shape
+	Parent *Order
+}
+`
+	pkg := typeCheck(t, src)
+	order := pkg.Scope().Lookup("Order").Type()
+	t.Cleanup(func() { SetStrictInterfaces(false) })
+
+	if is, why := IsSerializable(order); !is {
+		t.Errorf("expected interfaces to be accepted by default, got: %s", why)
+	}
+	SetStrictInterfaces(true)
+	is, why := IsSerializable(order)
+	if is {
+		t.Fatalf("expected interfaces to be reported")
+	}
+	for _, position := range []string{"Lines[].Meta[key] is any", "Shape is an interface", "Other is an interface", "Err is an error"} {
+		if !strings.Contains(why, position) {
+			t.Errorf("expected %q to be reported, got %q", position, why)
+		}
+	}
+}
+
+func TestLargeIntegers(t *testing.T) {
+	src := `package p
+
+type ID int64
+
+type Line struct {
+	ID     ID
+	Amount int64 ` + "`json:\",string\"`" + `
+	Count  int
+}
+
+type Order struct {
+	ID     uint64
+	Lines  []Line
+	Tags   map[string]int64
+	hidden int64
+	Parent *Order
+}
+`
+	pkg := typeCheck(t, src)
+	got := LargeIntegers(pkg.Scope().Lookup("Order").Type())
+	want := []string{"ID", "Lines[].ID", "Tags[key]"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := LargeIntegers(types.Typ[types.Int64]); !slices.Equal(got, []string{""}) {
+		t.Errorf("expected the value itself, got %v", got)
+	}
+	anyMap := types.NewMap(types.Typ[types.String], types.Universe.Lookup("any").Type())
+	if !IsInterfaceContainer(anyMap) {
+		t.Errorf("expected map[string]any to be an interface container")
+	}
+
+	// the numbers only lose their types in JSON
+	t.Cleanup(func() { _ = SetPayloadConverters(DefaultPayloadConverters) })
+	if !IsJSONPayload(anyMap) {
+		t.Errorf("expected map[string]any to be encoded into JSON by default")
+	}
+	if IsJSONPayload(types.NewSlice(types.Typ[types.Byte])) {
+		t.Errorf("expected []byte to be left as it is")
+	}
+	if err := SetPayloadConverters([]string{"nil", "[]byte", "gob"}); err != nil {
+		t.Fatal(err)
+	}
+	if IsJSONPayload(anyMap) {
+		t.Errorf("expected map[string]any to be encoded with gob")
+	}
+}
+
+func TestSensitiveFields(t *testing.T) {
+	src := `package p
+
+type Account struct {
+	UserSSN      string
+	BusinessName string
+	Secret       string ` + "`json:\"-\"`" + `
+	SecretName   string
+	Note         string ` + "`temporal:\"sensitive\"`" + `
+	Key          string ` + "`json:\"api_key\"`" + `
+	password     string
+}
+
+type Request struct {
+	Users         []Account
+	Password      string
+	AccessToken   string
+	Token         string
+	NextPageToken string
+	NextToken     string ` + "`json:\"next_token\"`" + `
+	Continuation  string ` + "`json:\"continuation_token\"`" + `
+	TokenCount    int
+	Next          *Request
+}
+
+type Packed struct {
+	Hint string ` + "`msgpack:\"passphrase\"`" + `
+}
+`
+	pkg := typeCheck(t, src)
+	got := SensitiveFields(pkg.Scope().Lookup("Request").Type())
+	slices.Sort(got)
+	want := []string{"AccessToken", "Password", "Token", "Users[].Key", "Users[].Note", "Users[].UserSSN"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// the fields are named by the encoding of the first converter accepting the payload
+	packed := pkg.Scope().Lookup("Packed").Type()
+	if got := SensitiveFields(packed); len(got) != 0 {
+		t.Errorf("expected no sensitive fields in JSON, got %v", got)
+	}
+	t.Cleanup(func() { _ = SetPayloadConverters(DefaultPayloadConverters) })
+	if err := SetPayloadConverters([]string{MsgpackConverter}); err != nil {
+		t.Fatal(err)
+	}
+	if got := SensitiveFields(packed); !slices.Equal(got, []string{"Hint"}) {
+		t.Errorf("expected Hint to be sensitive in msgpack, got %v", got)
+	}
+}
+
+func TestTypesMatch(t *testing.T) {
+	src := `package p
+
+type Order struct{ ID string }
+
+type Shape interface{ Area() float64 }
+
+type Square struct{ Side float64 }
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+`
+	pkg := typeCheck(t, src)
+	order := pkg.Scope().Lookup("Order").Type()
+	shape := pkg.Scope().Lookup("Shape").Type()
+	square := pkg.Scope().Lookup("Square").Type()
+	untypedNil := types.Typ[types.UntypedNil]
+	t.Cleanup(func() { SetStrictPointerMatch(false) })
+
+	tests := []struct {
+		expected, actual types.Type
+		want, strict     bool
+	}{
+		{order, order, true, true},
+		{order, types.NewPointer(order), true, false},
+		{types.NewPointer(order), order, true, false},
+		{order, untypedNil, true, false},
+		{order, types.Typ[types.String], false, false},
+		{shape, square, true, true},
+		{shape, types.Typ[types.String], false, false},
+		{square, shape, true, true},
+		{nil, order, true, true},
+	}
+	for _, tt := range tests {
+		for _, strict := range []bool{false, true} {
+			SetStrictPointerMatch(strict)
+			want := tt.want
+			if strict {
+				want = tt.strict
+			}
+			if got := TypesMatch(tt.expected, tt.actual); got != want {
+				t.Errorf("TypesMatch(%v, %v) with strict=%v: expected %v, got %v", tt.expected, tt.actual, strict, want, got)
+			}
+		}
+	}
+}
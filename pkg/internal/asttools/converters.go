@@ -0,0 +1,227 @@
+package asttools
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+	"sync"
+)
+
+// encoding are the rules of a payload converter encoding Go values structurally (as opposed to the converters
+// accepting only some types as they are, e.g. []byte), that IsSerializable checks the types with.
+type encoding struct {
+	// name is how the encoding is referred to in the reasons, e.g. encoding/json
+	name string
+	// tag is the struct tag key naming (or skipping) the fields, empty if the encoding has none
+	tag string
+	// marshalers are the pairs of methods of the types encoding (and decoding) themselves
+	marshalers [][2]string
+	// textKeys is true if the map keys have to be strings, integers or encoding.TextMarshaler
+	textKeys bool
+	// ignoresFuncs is true if the fields of channel and function types are skipped, rather than rejected
+	ignoresFuncs bool
+	// complexNumbers is true if complex64 and complex128 are supported
+	complexNumbers bool
+}
+
+var (
+	jsonEncoding = &encoding{
+		name:       "encoding/json",
+		tag:        "json",
+		marshalers: [][2]string{{"MarshalJSON", "UnmarshalJSON"}, {"MarshalText", "UnmarshalText"}},
+		textKeys:   true,
+	}
+	gobEncoding = &encoding{
+		name:           "encoding/gob",
+		marshalers:     [][2]string{{"GobEncode", "GobDecode"}, {"MarshalBinary", "UnmarshalBinary"}},
+		ignoresFuncs:   true,
+		complexNumbers: true,
+	}
+	msgpackEncoding = &encoding{
+		name:       "msgpack",
+		tag:        "msgpack",
+		marshalers: [][2]string{{"EncodeMsgpack", "DecodeMsgpack"}, {"MarshalMsgpack", "UnmarshalMsgpack"}, {"MarshalBinary", "UnmarshalBinary"}},
+	}
+)
+
+// Payload converters known to SetPayloadConverters. The first ones only accept some types (and leave the others
+// to the next converters), the encodings accept any type, so they end the chain.
+const (
+	NilConverter       = "nil"
+	ByteSliceConverter = "[]byte"
+	ProtoJSONConverter = "proto-json"
+	ProtoConverter     = "proto"
+	JSONConverter      = "json"
+	GobConverter       = "gob"
+	MsgpackConverter   = "msgpack"
+)
+
+// DefaultPayloadConverters is the chain of the Temporal SDK default data converter.
+var DefaultPayloadConverters = []string{NilConverter, ByteSliceConverter, ProtoJSONConverter, JSONConverter}
+
+var encodings = map[string]*encoding{
+	JSONConverter:    jsonEncoding,
+	GobConverter:     gobEncoding,
+	MsgpackConverter: msgpackEncoding,
+}
+
+// payloadConfig is the configuration of the payload converters IsSerializable follows.
+var payloadConfig = struct {
+	sync.Mutex
+	converters []string
+	// serializable are the qualified names of the types (and interfaces) always considered serializable
+	serializable map[string]bool
+	// strictInterfaces is true if interface types in the payloads are reported
+	strictInterfaces bool
+}{
+	converters:   DefaultPayloadConverters,
+	serializable: map[string]bool{},
+}
+
+// SetStrictInterfaces makes IsSerializable report the interface types (including any, and error)
+// in the payloads, which can't be decoded as the types that were sent. By default, they are assumed to be fine.
+func SetStrictInterfaces(strict bool) {
+	payloadConfig.Lock()
+	payloadConfig.strictInterfaces = strict
+	payloadConfig.Unlock()
+}
+
+func strictInterfaces() bool {
+	payloadConfig.Lock()
+	defer payloadConfig.Unlock()
+	return payloadConfig.strictInterfaces
+}
+
+// SetPayloadConverters sets the chain of payload converters of the data converter, e.g. nil, []byte, proto-json, json.
+// Like Temporal's composite data converter, each value is encoded by the first converter accepting it.
+func SetPayloadConverters(names []string) error {
+	var converters []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+			continue
+		case NilConverter, ByteSliceConverter, ProtoJSONConverter, ProtoConverter, JSONConverter, GobConverter, MsgpackConverter:
+			converters = append(converters, name)
+		default:
+			return fmt.Errorf("unknown payload converter %q, expected one of: %s", name, strings.Join([]string{
+				NilConverter, ByteSliceConverter, ProtoJSONConverter, ProtoConverter, JSONConverter, GobConverter, MsgpackConverter,
+			}, ", "))
+		}
+	}
+	payloadConfig.Lock()
+	payloadConfig.converters = converters
+	payloadConfig.Unlock()
+	resetSerializableCache()
+	return nil
+}
+
+// AddSerializableTypes adds types, by their qualified names (e.g. github.com/google/uuid.UUID), that are
+// always considered serializable, e.g. handled by a custom payload converter. For interfaces, the types
+// implementing them are considered serializable as well.
+func AddSerializableTypes(names []string) {
+	payloadConfig.Lock()
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			payloadConfig.serializable[name] = true
+		}
+	}
+	payloadConfig.Unlock()
+	resetSerializableCache()
+}
+
+// encodingFor returns the encoding of the first payload converter of the chain accepting the type,
+// or nil if the type is accepted as it is (e.g. []byte, or a proto message). Returns an error if no converter accepts it.
+func encodingFor(t types.Type) (*encoding, error) {
+	payloadConfig.Lock()
+	converters := payloadConfig.converters
+	payloadConfig.Unlock()
+	for _, name := range converters {
+		switch name {
+		case NilConverter:
+			if basic, ok := t.(*types.Basic); ok && basic.Kind() == types.UntypedNil {
+				return nil, nil
+			}
+		case ByteSliceConverter:
+			if isByteSlice(t) {
+				return nil, nil
+			}
+		case ProtoJSONConverter, ProtoConverter:
+			if isProtoMessage(t) {
+				return nil, nil
+			}
+		default:
+			return encodings[name], nil
+		}
+	}
+	return nil, fmt.Errorf("no payload converter of the chain (%s) accepts %s", strings.Join(converters, ", "), t)
+}
+
+// IsJSONPayload returns true if values of the type are encoded into JSON by the first payload converter
+// of the chain accepting them, and decoded by encoding/json (e.g. into float64 for numbers in interface values).
+func IsJSONPayload(t types.Type) bool {
+	t = types.Unalias(t)
+	if isAlwaysSerializable(t) {
+		// encoded by a custom payload converter
+		return false
+	}
+	enc, err := encodingFor(t)
+	return err == nil && enc == jsonEncoding
+}
+
+// isAlwaysSerializable returns true if the type (or the interface it implements) is configured to be serializable.
+func isAlwaysSerializable(t types.Type) bool {
+	payloadConfig.Lock()
+	defer payloadConfig.Unlock()
+	if len(payloadConfig.serializable) == 0 {
+		return false
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	if payloadConfig.serializable[named.String()] {
+		return true
+	}
+	// look for the configured interfaces in the packages the type's package knows of
+	pkg := named.Obj().Pkg()
+	if pkg == nil {
+		return false
+	}
+	packages := knownPackages(pkg)
+	for name := range payloadConfig.serializable {
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			continue
+		}
+		p, ok := packages[name[:i]]
+		if !ok {
+			continue
+		}
+		obj := p.Scope().Lookup(name[i+1:])
+		if obj == nil {
+			continue
+		}
+		iface, ok := obj.Type().Underlying().(*types.Interface)
+		if ok && (types.Implements(t, iface) || types.Implements(types.NewPointer(t), iface)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isByteSlice(t types.Type) bool {
+	slice, ok := t.(*types.Slice)
+	if !ok {
+		return false
+	}
+	basic, ok := slice.Elem().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+func isProtoMessage(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	return hasMethod(t, "ProtoMessage")
+}
@@ -0,0 +1,150 @@
+package asttools
+
+import (
+	"go/types"
+	"strings"
+	"unicode"
+)
+
+// SensitiveTag marks the fields holding secrets, whatever their names: `temporal:"sensitive"`.
+const SensitiveTag = "temporal"
+
+// sensitiveWords are the words of field names (or pairs of words, written together) that mark secrets,
+// e.g. Password, APIKey, or user_ssn.
+var sensitiveWords = map[string]bool{
+	"password": true, "passwords": true, "passwd": true, "passphrase": true, "token": true, "tokens": true,
+	"secret": true, "secrets": true, "credential": true, "credentials": true, "ssn": true, "cvv": true,
+	"apikey": true, "privatekey": true, "accesskey": true, "secretkey": true,
+	"creditcard": true, "cardnumber": true, "socialsecurity": true,
+}
+
+// referenceWords follow sensitive words in the names of fields that refer to a secret, without holding it,
+// e.g. SecretName, or PasswordID. The pairs of words, written together, are the ones a sensitive word ends,
+// that name something else, e.g. the pagination tokens: NextPageToken is not a secret.
+var referenceWords = map[string]bool{
+	"name": true, "id": true, "ref": true, "arn": true, "path": true, "version": true, "count": true,
+	"pagetoken": true, "nexttoken": true, "continuationtoken": true,
+}
+
+// SensitiveFields returns the paths of the fields of the type (e.g. Users[].Password) that are encoded into payloads,
+// and hold secrets by their names (or the names they are encoded under), or their `temporal:"sensitive"` tags.
+// Payloads are stored in the workflow history as they are encoded, in plain text unless a codec encrypts them.
+// Like IsSerializable, the fields are the ones encoded by the first payload converter accepting the type.
+// Types with custom encodings are not looked into, except proto messages, which encode their fields.
+func SensitiveFields(t types.Type) []string {
+	t = types.Unalias(t)
+	if isAlwaysSerializable(t) {
+		return nil
+	}
+	enc, err := encodingFor(t)
+	if err != nil {
+		return nil
+	}
+	if enc == nil {
+		if !isProtoMessage(t) {
+			// e.g. []byte, no fields
+			return nil
+		}
+		// protojson names the fields like their json tags
+		enc = jsonEncoding
+	}
+	var positions []string
+	visited := map[*types.Named]bool{}
+	var walk func(t types.Type, path string)
+	walk = func(t types.Type, path string) {
+		t = types.Unalias(t)
+		switch t := t.(type) {
+		case *types.Named:
+			if visited[t] {
+				return
+			}
+			visited[t] = true
+			defer delete(visited, t)
+			if _, custom := checkMarshalers(t, enc); custom && !isProtoMessage(t) {
+				return
+			}
+			walk(t.Underlying(), path)
+		case *types.Pointer:
+			walk(t.Elem(), path)
+		case *types.Slice:
+			walk(t.Elem(), path+"[]")
+		case *types.Array:
+			walk(t.Elem(), path+"[]")
+		case *types.Map:
+			walk(t.Elem(), path+"[key]")
+		case *types.Struct:
+			fields, _ := encodedFields(t, enc)
+			for _, f := range fields {
+				fieldPath := f.path
+				if path != "" {
+					fieldPath = path + "." + f.path
+				}
+				if isSensitive(f) {
+					positions = append(positions, fieldPath)
+					continue
+				}
+				walk(f.field.Type(), fieldPath)
+			}
+		}
+	}
+	walk(t, "")
+	return positions
+}
+
+func isSensitive(f encodedField) bool {
+	if f.tag.Get(SensitiveTag) == "sensitive" {
+		return true
+	}
+	return isSensitiveName(f.field.Name()) || isSensitiveName(f.name)
+}
+
+// isSensitiveName returns true if one of the words of the name, or two consecutive ones, are sensitive words,
+// not followed by a word making the field a reference to the secret, nor part of a pair naming something else.
+// Whole words are compared, so that e.g. BusinessName is not taken for an SSN.
+func isSensitiveName(name string) bool {
+	words := splitWords(name)
+	for i, word := range words {
+		if !sensitiveWords[word] && (i == 0 || !sensitiveWords[words[i-1]+word]) {
+			continue
+		}
+		if i+1 < len(words) && referenceWords[words[i+1]] {
+			continue
+		}
+		if i > 0 && referenceWords[words[i-1]+word] {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// splitWords splits a Go (camel case, with initialisms), snake case or kebab case name into lower case words,
+// e.g. APIKey into api, key, and user_ssn into user, ssn.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = nil
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// a new word: userName, or the last letter of an initialism followed by a word: APIKey
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,619 @@
+package asttools
+
+import (
+	"fmt"
+	"go/types"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// serializability is the result of checking a type: if it's serializable, and if not, why.
+type serializability struct {
+	ok  bool
+	why string
+}
+
+// serializableCache remembers the serializability of the types checked so far (by type identity, per encoding),
+// as the same types are checked over and over, e.g. for every call of a workflow.
+// It's shared by the analyzers running concurrently, so it's guarded by a mutex.
+var serializableCache struct {
+	sync.Mutex
+	types map[*encoding]*typeutil.Map
+	// packages are the packages known to each package (itself, and the ones it imports), by path
+	packages map[*types.Package]map[string]*types.Package
+}
+
+func resetSerializableCache() {
+	serializableCache.Lock()
+	serializableCache.types = map[*encoding]*typeutil.Map{}
+	serializableCache.packages = map[*types.Package]map[string]*types.Package{}
+	serializableCache.Unlock()
+}
+
+// knownPackages returns the package and the packages it imports, directly or not, by path.
+// The result is shared, and must not be modified.
+func knownPackages(pkg *types.Package) map[string]*types.Package {
+	serializableCache.Lock()
+	defer serializableCache.Unlock()
+	packages, ok := serializableCache.packages[pkg]
+	if !ok {
+		packages = ImportedPackages(pkg)
+		packages[pkg.Path()] = pkg
+		serializableCache.packages[pkg] = packages
+	}
+	return packages
+}
+
+func init() {
+	resetSerializableCache()
+}
+
+// IsSerializable returns true if the given type is serializable by the payload converters (see SetPayloadConverters),
+// by default to JSON. This is a very rough approximation, but it's good enough for our purposes.
+// Returns if the type is serializable, and if not, why.
+// Recursive types (e.g. type Node struct{ Children []*Node }) are supported, and the results are cached.
+func IsSerializable(t types.Type) (bool, string) {
+	t = types.Unalias(t)
+	if isAlwaysSerializable(t) {
+		return true, ""
+	}
+	enc, err := encodingFor(t)
+	if err != nil {
+		return false, err.Error()
+	}
+	if enc == nil {
+		// accepted as it is
+		return true, ""
+	}
+	c := serializableChecker{enc: enc}
+	result, _ := c.check(t)
+	if result.ok && strictInterfaces() {
+		if positions := interfacePositions(t, enc); len(positions) > 0 {
+			return false, strings.Join(positions, "\n\t")
+		}
+	}
+	return result.ok, result.why
+}
+
+// serializableChecker checks a type, keeping track of the named types being checked, to detect cycles.
+type serializableChecker struct {
+	// enc are the rules of the payload converter encoding the type
+	enc *encoding
+	// stack are the named types being checked, from the outermost one
+	stack []*types.Named
+}
+
+// noAssumption is the depth returned by check when the result does not depend on a type that's still being checked.
+const noAssumption = math.MaxInt
+
+// check returns the serializability of the type, and the depth of the outermost type that's still being checked,
+// which the result assumes to be serializable (if it refers back to it).
+// Such results are only known once that type is checked, so they are not cached before.
+func (c *serializableChecker) check(t types.Type) (serializability, int) {
+	t = types.Unalias(t)
+	serializableCache.Lock()
+	cache, ok := serializableCache.types[c.enc]
+	if !ok {
+		cache = new(typeutil.Map)
+		serializableCache.types[c.enc] = cache
+	}
+	cached, ok := cache.At(t).(serializability)
+	serializableCache.Unlock()
+	if ok {
+		return cached, noAssumption
+	}
+
+	depth := noAssumption
+	if named, ok := t.(*types.Named); ok {
+		for i, n := range c.stack {
+			if n == named {
+				// a cycle, e.g. Node -> []*Node -> Node: the type is serializable as long as the rest of it is,
+				// which is what is being checked
+				return serializability{ok: true}, i
+			}
+		}
+		depth = len(c.stack)
+		c.stack = append(c.stack, named)
+		defer func() { c.stack = c.stack[:depth] }()
+	}
+
+	result, assumed := c.checkType(t)
+	// a type that's not serializable is not, whatever the types it refers to turn out to be;
+	// otherwise we know for sure once all the types it refers to are checked
+	if !result.ok || assumed >= depth {
+		serializableCache.Lock()
+		cache.Set(t, result)
+		serializableCache.Unlock()
+		assumed = noAssumption
+	}
+	return result, assumed
+}
+
+func (c *serializableChecker) checkType(t types.Type) (serializability, int) {
+	if isAlwaysSerializable(t) {
+		return serializability{ok: true, why: "is configured to be serializable"}, noAssumption
+	}
+	// if the type has a custom Marshaler, it means the author of the type
+	// knows how to serialize it, as long as it can also be decoded back
+	if named, ok := t.(*types.Named); ok {
+		if result, custom := checkMarshalers(named, c.enc); custom {
+			return result, noAssumption
+		}
+	}
+
+	switch t := t.(type) {
+	case *types.Struct:
+		return c.checkStruct(t)
+	case *types.Pointer:
+		return c.check(t.Elem())
+	case *types.Named:
+		return c.check(t.Underlying())
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Complex64, types.Complex128, types.UntypedComplex:
+			if c.enc.complexNumbers {
+				break
+			}
+			return notSerializable("%s is a complex number, %s does not support them", t, c.enc.name)
+		case types.UnsafePointer:
+			return notSerializable("unsafe.Pointer cannot be serialized, %s does not support it", c.enc.name)
+		case types.Invalid:
+			return notSerializable("the type could not be determined")
+		}
+		return serializability{ok: true}, noAssumption
+	case *types.Chan:
+		return notSerializable("%s is a channel, %s does not support them", t, c.enc.name)
+	case *types.Signature:
+		return notSerializable("%s is a function, %s does not support them", t, c.enc.name)
+	case *types.Slice:
+		return c.check(t.Elem())
+	case *types.Array:
+		return c.check(t.Elem())
+	case *types.Map:
+		if why := checkMapKey(t.Key(), c.enc); why != "" {
+			return notSerializable("map key (%s) is not serializable,\n\treason: %s", t.Key().String(), why)
+		}
+		return c.check(t.Elem())
+	case *types.Interface:
+		// if it's an interface, we can't know what it is, so make an optimistic assumption
+		// (unless interfaces are reported, see SetStrictInterfaces)
+		return serializability{ok: true}, noAssumption
+	default:
+		// if we don't know what it is, assume it's not serializable
+		return notSerializable("type %s is not serializable (most likely)", t.String())
+	}
+}
+
+// checkStruct checks the fields of the struct the way encoding/json encodes them (and approximately, the way
+// the other encodings do): unexported fields are dropped (even if tagged), the ones tagged with json:"-"
+// are skipped on purpose, and the fields of embedded structs are promoted, unless they are hidden by,
+// or collide with other fields of the same JSON name.
+// All the fields that would be dropped by accident are reported, not only the first one.
+func (c *serializableChecker) checkStruct(t *types.Struct) (serializability, int) {
+	fields, droppedFields := encodedFields(t, c.enc)
+	assumed := noAssumption
+	var dropped, skipped []string
+	for _, d := range droppedFields {
+		if d.intentional {
+			skipped = append(skipped, d.path)
+			continue
+		}
+		dropped = append(dropped, fmt.Sprintf("field %s is not serializable,\n\treason: %s", d.path, d.reason))
+	}
+	for _, f := range fields {
+		result, fieldAssumed := c.check(f.field.Type())
+		if !result.ok {
+			dropped = append(dropped, fmt.Sprintf("field %s (%s) is not serializable,\n\treason: %s",
+				f.path, f.field.Type().String(), result.why))
+		}
+		assumed = min(assumed, fieldAssumed)
+	}
+	if len(dropped) == 0 {
+		return serializability{ok: true}, assumed
+	}
+	why := strings.Join(dropped, "\n\t")
+	if len(skipped) > 0 {
+		why += fmt.Sprintf("\n\t(skipped on purpose: %s)", strings.Join(skipped, ", "))
+	}
+	return serializability{why: why}, noAssumption
+}
+
+// encodedField is a field encoding/json encodes under name, found at path (e.g. Embedded.Field)
+// in the struct, depth embedded structs deep.
+type encodedField struct {
+	name   string
+	path   string
+	field  *types.Var
+	depth  int
+	tagged bool
+	// quoted is true for the fields tagged with the string option, e.g. `json:"id,string"`
+	quoted bool
+	// tag is the whole struct tag of the field, with the tags of other tools, e.g. `temporal:"sensitive"`
+	tag reflect.StructTag
+}
+
+// droppedField is a field encoding/json does not encode, on purpose (json:"-") or not.
+type droppedField struct {
+	path        string
+	reason      string
+	intentional bool
+}
+
+// encodedFields returns the fields encoding/json (or the other encodings, using their struct tags) encodes
+// for the struct, and the ones it drops.
+// Like encoding/json, it promotes the fields of embedded structs (exported or not, by value or by pointer),
+// breadth first, and for every JSON name keeps the shallowest field. Fields of the same name at the same depth
+// collide and are all dropped, unless exactly one of them is tagged with the name.
+func encodedFields(t *types.Struct, enc *encoding) ([]encodedField, []droppedField) {
+	type embedded struct {
+		s     *types.Struct
+		path  string
+		depth int
+	}
+	var dropped []droppedField
+	byName := map[string][]encodedField{}
+	visited := map[types.Type]bool{}
+	for current := []embedded{{s: t}}; len(current) > 0; {
+		var next []embedded
+		for _, e := range current {
+			for i := 0; i < e.s.NumFields(); i++ {
+				f := e.s.Field(i)
+				path := f.Name()
+				if e.path != "" {
+					path = e.path + "." + f.Name()
+				}
+				var tag fieldTag
+				if enc.tag != "" {
+					tag = parseTag(reflect.StructTag(e.s.Tag(i)).Get(enc.tag))
+				}
+				if tag.skip {
+					dropped = append(dropped, droppedField{path: path,
+						reason: fmt.Sprintf(`field is marked with %s:"-"`, enc.tag), intentional: true})
+					continue
+				}
+				if enc.ignoresFuncs && f.Exported() {
+					switch f.Type().Underlying().(type) {
+					case *types.Chan, *types.Signature:
+						dropped = append(dropped, droppedField{path: path,
+							reason: fmt.Sprintf("%s ignores fields of channel and function types", enc.name), intentional: true})
+						continue
+					}
+				}
+				if f.Anonymous() && tag.name == "" && isStruct(f.Type()) {
+					ft := types.Unalias(f.Type())
+					if ptr, isPtr := ft.(*types.Pointer); isPtr {
+						if !f.Exported() {
+							dropped = append(dropped, droppedField{path: path,
+								reason: fmt.Sprintf("embedded pointer to an unexported struct, %s cannot decode into it", enc.name)})
+							continue
+						}
+						ft = ptr.Elem()
+					}
+					if !visited[ft] {
+						visited[ft] = true
+						next = append(next, embedded{s: ft.Underlying().(*types.Struct), path: path, depth: e.depth + 1})
+					}
+					continue
+				}
+				// embedded structs tagged with a name are encoded as a field, even if their type is not exported
+				if !f.Exported() && !(f.Anonymous() && isStruct(f.Type())) {
+					reason := "field is not exported"
+					if tag.name != "" || len(tag.options) > 0 {
+						reason = fmt.Sprintf("field is not exported, its %s tag has no effect", enc.tag)
+					}
+					dropped = append(dropped, droppedField{path: path, reason: reason})
+					continue
+				}
+				name := tag.name
+				if name == "" {
+					name = f.Name()
+				}
+				byName[name] = append(byName[name], encodedField{
+					name: name, path: path, field: f, depth: e.depth, tagged: tag.name != "",
+					quoted: slices.Contains(tag.options, "string"), tag: reflect.StructTag(e.s.Tag(i)),
+				})
+			}
+		}
+		current = next
+	}
+
+	var fields []encodedField
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		candidates := byName[name]
+		// the fields are found breadth first, so the shallowest ones come first
+		shallowest := slices.IndexFunc(candidates, func(f encodedField) bool { return f.depth > candidates[0].depth })
+		if shallowest < 0 {
+			shallowest = len(candidates)
+		}
+		dominant := candidates[:shallowest]
+		if len(dominant) > 1 {
+			var tagged []encodedField
+			for _, f := range dominant {
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+			if len(tagged) != 1 {
+				for _, f := range dominant {
+					dropped = append(dropped, droppedField{path: f.path,
+						reason: fmt.Sprintf("name %q collides with other fields at the same depth (%s), "+
+							"%s drops all of them", name, strings.Join(paths(dominant), ", "), enc.name)})
+				}
+				for _, f := range candidates[shallowest:] {
+					dropped = append(dropped, droppedField{path: f.path,
+						reason: fmt.Sprintf("hidden by the colliding fields of name %q", name)})
+				}
+				continue
+			}
+			dominant = tagged
+		}
+		fields = append(fields, dominant[0])
+		for _, f := range candidates {
+			if f.path != dominant[0].path {
+				dropped = append(dropped, droppedField{path: f.path,
+					reason: fmt.Sprintf("hidden by %s, encoded under the same name %q", dominant[0].path, name)})
+			}
+		}
+	}
+	return fields, dropped
+}
+
+func paths(fields []encodedField) []string {
+	var result []string
+	for _, f := range fields {
+		result = append(result, f.path)
+	}
+	return result
+}
+
+// fieldTag is a parsed json (or msgpack) struct tag, e.g. `json:"name,omitempty,string"`.
+type fieldTag struct {
+	// name is the name of the field in JSON, empty for the name of the Go field
+	name string
+	// skip is true for the fields that are never encoded: `json:"-"` (but not `json:"-,"`, named "-")
+	skip bool
+	// options are the options after the name: omitempty (or omitzero), which only drop empty values,
+	// and string, which encodes numbers and booleans as JSON strings
+	options []string
+}
+
+func parseTag(tag string) fieldTag {
+	if tag == "-" {
+		return fieldTag{skip: true}
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	parsed := fieldTag{name: name}
+	if options != "" {
+		parsed.options = strings.Split(options, ",")
+	}
+	return parsed
+}
+
+// isStruct returns true for structs and pointers to structs.
+func isStruct(t types.Type) bool {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// checkMarshalers checks the custom encoding of the type, if it has one: a protobuf message, or e.g. MarshalJSON
+// (or MarshalText, encoded as a JSON string), which needs the UnmarshalJSON (or UnmarshalText) counterpart
+// on the pointer, for the value to be decoded back. Returns false if the type has no custom encoding.
+func checkMarshalers(t *types.Named, enc *encoding) (serializability, bool) {
+	if hasMethod(t, "ProtoMessage") {
+		return serializability{ok: true, why: "is a protobuf message"}, true
+	}
+	for _, pair := range enc.marshalers {
+		marshal, unmarshal := pair[0], pair[1]
+		if !hasMethod(t, marshal) {
+			continue
+		}
+		if why := checkUnmarshaler(t, unmarshal); why != "" {
+			// text is a JSON string, which UnmarshalJSON can decode as well
+			if marshal == "MarshalText" && checkUnmarshaler(t, "UnmarshalJSON") == "" {
+				return serializability{ok: true, why: "implements MarshalText and UnmarshalJSON"}, true
+			}
+			result, _ := notSerializable("%s implements %s, but %s, so it cannot be decoded back",
+				t.Obj().Name(), marshal, why)
+			return result, true
+		}
+		return serializability{ok: true, why: "implements " + marshal + " and " + unmarshal}, true
+	}
+	return serializability{}, false
+}
+
+// checkUnmarshaler returns why the type cannot be decoded with the method, or an empty string if it can:
+// the decoder calls it on a pointer, so it has to have a pointer receiver to set the value.
+func checkUnmarshaler(t *types.Named, method string) string {
+	sel := types.NewMethodSet(types.NewPointer(t)).Lookup(nil, method)
+	if sel == nil {
+		return fmt.Sprintf("*%s does not implement %s", t.Obj().Name(), method)
+	}
+	recv := sel.Obj().(*types.Func).Signature().Recv()
+	if _, isPtr := types.Unalias(recv.Type()).(*types.Pointer); !isPtr {
+		return fmt.Sprintf("its %s has a value receiver, and cannot set the decoded value", method)
+	}
+	return ""
+}
+
+// checkMapKey returns why encoding/json cannot use the type as a map key, or an empty string if it can:
+// keys have to be strings or integers (whatever their methods), or implement encoding.TextMarshaler
+// (and encoding.TextUnmarshaler).
+// The other encodings accept any key.
+func checkMapKey(t types.Type, enc *encoding) string {
+	if !enc.textKeys {
+		return ""
+	}
+	t = types.Unalias(t)
+	if basic, ok := t.Underlying().(*types.Basic); ok && basic.Info()&(types.IsString|types.IsInteger) != 0 {
+		return ""
+	}
+	if named, ok := t.(*types.Named); ok && hasMethod(named, "MarshalText") {
+		if why := checkUnmarshaler(named, "UnmarshalText"); why != "" {
+			return fmt.Sprintf("%s implements MarshalText, but %s, so it cannot be decoded back", named.Obj().Name(), why)
+		}
+		return ""
+	}
+	return "map keys must be strings, integers, or implement encoding.TextMarshaler"
+}
+
+// hasMethod returns true if the method is in the method set of the type, or of the pointer to it
+// (encoding/json uses the methods with pointer receivers for addressable values).
+func hasMethod(t types.Type, name string) bool {
+	return types.NewMethodSet(types.NewPointer(t)).Lookup(nil, name) != nil
+}
+
+func notSerializable(format string, args ...any) (serializability, int) {
+	return serializability{why: fmt.Sprintf(format, args...)}, noAssumption
+}
+
+// interfacePositions returns the positions of interface types in the encoded value of the type, by their full path
+// (e.g. Items[].Metadata), with why each of them cannot be decoded as it was sent.
+// The types with custom encodings, or configured to be serializable, are not looked into.
+func interfacePositions(t types.Type, enc *encoding) []string {
+	var positions []string
+	visited := map[*types.Named]bool{}
+	var walk func(t types.Type, path string)
+	walk = func(t types.Type, path string) {
+		t = types.Unalias(t)
+		if isAlwaysSerializable(t) {
+			return
+		}
+		switch t := t.(type) {
+		case *types.Named:
+			// the types being walked, e.g. Node in Node.Children[]: the rest of it is walked already
+			if visited[t] {
+				return
+			}
+			visited[t] = true
+			defer delete(visited, t)
+			if _, custom := checkMarshalers(t, enc); custom {
+				return
+			}
+			if iface, ok := t.Underlying().(*types.Interface); ok {
+				positions = append(positions, interfacePosition(t, iface, path))
+				return
+			}
+			walk(t.Underlying(), path)
+		case *types.Interface:
+			positions = append(positions, interfacePosition(t, t, path))
+		case *types.Pointer:
+			walk(t.Elem(), path)
+		case *types.Slice:
+			walk(t.Elem(), path+"[]")
+		case *types.Array:
+			walk(t.Elem(), path+"[]")
+		case *types.Map:
+			walk(t.Elem(), path+"[key]")
+		case *types.Struct:
+			fields, _ := encodedFields(t, enc)
+			for _, f := range fields {
+				fieldPath := f.path
+				if path != "" {
+					fieldPath = path + "." + f.path
+				}
+				walk(f.field.Type(), fieldPath)
+			}
+		}
+	}
+	walk(t, "")
+	return positions
+}
+
+// interfacePosition explains why the interface at the path cannot be decoded as it was sent.
+func interfacePosition(t types.Type, iface *types.Interface, path string) string {
+	at := "the value"
+	if path != "" {
+		at = path
+	}
+	switch {
+	case t.String() == "error":
+		return fmt.Sprintf("%s is an error: errors are encoded as their exported fields (usually none, as {}), "+
+			"and cannot be decoded back into an error. Pass the message as a string instead, "+
+			"or return the error from the workflow/activity", at)
+	case iface.Empty():
+		name := "any"
+		if named, ok := t.(*types.Named); ok {
+			name = named.Obj().Name() + " (any)"
+		}
+		return fmt.Sprintf("%s is %s: it's decoded as map[string]interface{} (or []interface{}, float64, string, bool), "+
+			"not as the type that was sent", at, name)
+	default:
+		return fmt.Sprintf("%s is an interface (%s): it cannot be decoded into, the receiving side fails", at, t)
+	}
+}
+
+// LargeIntegers returns the positions of int64 and uint64 values in the JSON encoding of the type, by their path
+// (empty for the value itself, e.g. Items[].ID for a field), which lose precision above 2^53 when they are decoded
+// into interface values (as float64). Fields encoded as strings (`json:",string"`), and types with custom encodings
+// are not included. It only applies to JSON payloads, see IsJSONPayload.
+func LargeIntegers(t types.Type) []string {
+	var positions []string
+	visited := map[*types.Named]bool{}
+	var walk func(t types.Type, path string)
+	walk = func(t types.Type, path string) {
+		t = types.Unalias(t)
+		switch t := t.(type) {
+		case *types.Named:
+			if visited[t] {
+				return
+			}
+			visited[t] = true
+			defer delete(visited, t)
+			if _, custom := checkMarshalers(t, jsonEncoding); custom {
+				return
+			}
+			walk(t.Underlying(), path)
+		case *types.Basic:
+			if t.Kind() == types.Int64 || t.Kind() == types.Uint64 {
+				positions = append(positions, path)
+			}
+		case *types.Pointer:
+			walk(t.Elem(), path)
+		case *types.Slice:
+			walk(t.Elem(), path+"[]")
+		case *types.Array:
+			walk(t.Elem(), path+"[]")
+		case *types.Map:
+			walk(t.Elem(), path+"[key]")
+		case *types.Struct:
+			fields, _ := encodedFields(t, jsonEncoding)
+			for _, f := range fields {
+				if f.quoted {
+					continue
+				}
+				fieldPath := f.path
+				if path != "" {
+					fieldPath = path + "." + f.path
+				}
+				walk(f.field.Type(), fieldPath)
+			}
+		}
+	}
+	walk(t, "")
+	return positions
+}
+
+// IsInterfaceContainer returns true for interfaces, and maps, slices and arrays of interfaces (e.g. map[string]any),
+// which the decoder fills with the default types for JSON values: float64 for all the numbers.
+func IsInterfaceContainer(t types.Type) bool {
+	switch t := types.Unalias(t).Underlying().(type) {
+	case *types.Interface:
+		return true
+	case *types.Map:
+		return IsInterfaceContainer(t.Elem())
+	case *types.Slice:
+		return IsInterfaceContainer(t.Elem())
+	case *types.Array:
+		return IsInterfaceContainer(t.Elem())
+	}
+	return false
+}
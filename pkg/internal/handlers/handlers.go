@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+)
+
+// Type is a type in a form that can be exported as a fact, and resolved back in the packages importing it:
+// a type expression with the packages it refers to named p0, p1... after their index in Imports,
+// e.g. []*p0.Order with Imports [example.com/orders].
+type Type struct {
+	Expr    string
+	Imports []string
+	// t is the type itself, known in the package it comes from (facts don't carry it)
+	t types.Type
+}
+
+// NewType returns the Type for t, the unknown (empty) Type for nil.
+func NewType(t types.Type) Type {
+	if t == nil {
+		return Type{}
+	}
+	result := Type{t: t}
+	aliases := map[string]string{}
+	result.Expr = types.TypeString(t, func(p *types.Package) string {
+		alias, ok := aliases[p.Path()]
+		if !ok {
+			alias = fmt.Sprintf("p%d", len(result.Imports))
+			aliases[p.Path()] = alias
+			result.Imports = append(result.Imports, p.Path())
+		}
+		return alias
+	})
+	return result
+}
+
+// String returns the qualified type string, e.g. []*example.com/orders.Order.
+func (t Type) String() string {
+	if t.t != nil {
+		return t.t.String()
+	}
+	expr := t.Expr
+	for i := len(t.Imports) - 1; i >= 0; i-- {
+		expr = strings.ReplaceAll(expr, fmt.Sprintf("p%d.", i), t.Imports[i]+".")
+	}
+	return expr
+}
+
+// Resolver resolves the Types exported as facts in the packages imported by a package.
+type Resolver struct {
+	fset     *token.FileSet
+	packages map[string]*types.Package
+}
+
+// NewResolver returns a Resolver for the types of the packages imported by pkg, directly or not.
+func NewResolver(fset *token.FileSet, pkg *types.Package) Resolver {
+	packages := asttools.ImportedPackages(pkg)
+	packages[pkg.Path()] = pkg
+	return Resolver{fset: fset, packages: packages}
+}
+
+// Resolve returns the type t stands for, or nil if it's unknown, or cannot be resolved
+// (e.g. it refers to an unexported type of another package).
+func (r Resolver) Resolve(t Type) types.Type {
+	if t.t != nil || t.Expr == "" {
+		return t.t
+	}
+	scope := types.NewPackage("temporalio/handlers", "handlers")
+	for i, path := range t.Imports {
+		pkg, ok := r.packages[path]
+		if !ok {
+			return nil
+		}
+		scope.Scope().Insert(types.NewPkgName(token.NoPos, scope, fmt.Sprintf("p%d", i), pkg))
+	}
+	tv, err := types.Eval(r.fset, scope, token.NoPos, t.Expr)
+	if err != nil || !tv.IsType() {
+		return nil
+	}
+	return tv.Type
+}
+
+// Handler is the signature of a query or update handler registered by a workflow,
+// in a form that can be exported as a fact and checked against in other packages.
+// For variadic handlers, the last parameter is the type of each of the trailing arguments.
+type Handler struct {
+	Params   []Type
+	Variadic bool
+	// Result is the type of the non-error result, unknown (empty) if the handler returns only an error.
+	Result Type
+}
+
+// FromSignature returns the Handler for the given signature.
+// A leading workflow.Context parameter is not passed by callers, so it's left out.
+func FromSignature(sig *types.Signature) Handler {
+	h := Handler{Variadic: sig.Variadic()}
+	for i := range sig.Params().Len() {
+		t := sig.Params().At(i).Type()
+		if i == 0 && external.WorkflowCtx.MatchString(t.String()) {
+			continue
+		}
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			t = t.(*types.Slice).Elem()
+		}
+		h.Params = append(h.Params, NewType(t))
+	}
+	if sig.Results().Len() == 2 {
+		h.Result = NewType(sig.Results().At(0).Type())
+	}
+	return h
+}
+
+// CheckArgs checks the number and types of call arguments against the handlers registered under the name,
+// the same way workflow and activity calls are checked. Workflows can register handlers of the same name
+// with different signatures, so the call is only reported if none of them accepts the arguments.
+func CheckArgs(pass *analysis.Pass, r Resolver, pos token.Pos, kind, name string, hs []Handler, callArgs []ast.Expr) {
+	var problems [][]string
+	for _, h := range hs {
+		p := argProblems(pass, r, kind, name, h, callArgs)
+		if len(p) == 0 {
+			return
+		}
+		problems = append(problems, p)
+	}
+	switch len(problems) {
+	case 0:
+		return
+	case 1:
+		for _, p := range problems[0] {
+			pass.Reportf(pos, "%s", p)
+		}
+	default:
+		var all []string
+		for _, p := range problems {
+			all = append(all, p...)
+		}
+		pass.Reportf(pos, "None of the %d handlers of %s `%s` accepts the arguments:\n\t%s", len(problems), kind, name,
+			strings.Join(all, "\n\t"))
+	}
+}
+
+// argProblems returns what's wrong with passing the call arguments to the handler.
+func argProblems(pass *analysis.Pass, r Resolver, kind, name string, h Handler, callArgs []ast.Expr) []string {
+	var problems []string
+	expected := len(h.Params)
+	if !h.Variadic {
+		if expected < len(callArgs) {
+			problems = append(problems, fmt.Sprintf("Too many arguments to %s `%s` - expected %d, got %d",
+				kind, name, expected, len(callArgs)))
+		}
+		if expected > len(callArgs) {
+			problems = append(problems, fmt.Sprintf("Too few arguments to %s `%s` - expected %d, got %d",
+				kind, name, expected, len(callArgs)))
+		}
+	} else if len(callArgs) < expected-1 {
+		problems = append(problems, fmt.Sprintf("Too few arguments to %s `%s` - expected at least %d, got %d",
+			kind, name, expected-1, len(callArgs)))
+	}
+
+	for argIdx, arg := range callArgs {
+		var expectedT Type
+		switch {
+		case h.Variadic && argIdx >= expected-1:
+			expectedT = h.Params[expected-1]
+		case argIdx < expected:
+			expectedT = h.Params[argIdx]
+		default:
+			continue
+		}
+		actualT := pass.TypesInfo.TypeOf(arg)
+		if !asttools.TypesMatch(r.Resolve(expectedT), actualT) {
+			problems = append(problems, fmt.Sprintf("Type of %s argument to %s `%s` does not match the type of the handler\n"+
+				"\tExpected: %s,\n\t     got: %s", asttools.NumberToOrdinal(argIdx+1), kind, name, expectedT, actualT))
+		}
+	}
+	return problems
+}
+
+// SameParams returns true if both handlers (of this package) take the same arguments.
+func SameParams(a, b Handler) bool {
+	if a.Variadic != b.Variadic || len(a.Params) != len(b.Params) {
+		return false
+	}
+	for i := range a.Params {
+		if !types.Identical(a.Params[i].t, b.Params[i].t) {
+			return false
+		}
+	}
+	return true
+}
@@ -13,9 +13,13 @@ type TemporalCall struct {
 	CallName string
 	Expr     *ast.CallExpr
 
-	Type     TemporalIoCallType
-	Callee   types.Object
-	CallArgs []ast.Expr
+	Type TemporalIoCallType
+	// CalleeExpr is the argument identifying the callee: a function, a method value, or a name
+	CalleeExpr ast.Expr
+	Callee     types.Object
+	CallArgs   []ast.Expr
+	// TaskQueue is the task queue the call is routed to by its options, empty if it can't be determined
+	TaskQueue string
 }
 
 type TemporalIoCallType int
@@ -0,0 +1,192 @@
+package queries
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	goTypes "go/types"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "TemporalioQueries",
+	Doc:       "Checks Temporal.io query handlers, and that queries are made with the arguments and result types of their handlers.",
+	Run:       run,
+	Flags:     flag.FlagSet{},
+	FactTypes: []analysis.Fact{new(queriesFact)},
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&debug, "debug-queries", false,
+		"Enable debug mode")
+	pflag.CommandLine.AddGoFlagSet(&Analyzer.Flags)
+}
+
+var debug bool
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	registered := identifyHandlers(pass)
+	if len(registered) > 0 {
+		pass.ExportPackageFact(&queriesFact{Handlers: registered})
+	}
+
+	// handlers from this package, and the packages it imports
+	known := map[string][]handlers.Handler{}
+	for name, hs := range registered {
+		known[name] = append(known[name], hs...)
+	}
+	for _, f := range pass.AllPackageFacts() {
+		if fact, ok := f.Fact.(*queriesFact); ok && f.Package != pass.Pkg {
+			for name, hs := range fact.Handlers {
+				known[name] = append(known[name], hs...)
+			}
+		}
+	}
+	resolver := handlers.NewResolver(pass.Fset, pass.Pkg)
+	if debug {
+		fmt.Printf("Found %d query handlers, %d known in total\n", len(registered), len(known))
+	}
+
+	// query results, by the variable holding them
+	results := map[goTypes.Object]string{}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				// value, err := client.QueryWorkflow(ctx, workflowID, runID, queryType, args...)
+				if len(n.Rhs) != 1 {
+					return true
+				}
+				call, ok := n.Rhs[0].(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if name, ok := queryName(pass, call); ok {
+					if o := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(n.Lhs[0])); o != nil {
+						results[o] = name
+					}
+				}
+			case *ast.CallExpr:
+				if name, ok := queryName(pass, n); ok {
+					checkQueryArgs(pass, resolver, n, name, known[name])
+				}
+				if asttools.IsMethodCall(pass.TypesInfo, n, external.EncodedValueType, external.Get) {
+					name, ok := results[pass.TypesInfo.ObjectOf(asttools.IdentifierOf(n.Fun.(*ast.SelectorExpr).X))]
+					if ok && len(n.Args) == 1 {
+						checkResultTarget(pass, resolver, n.Args[0], name, known[name])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// identifyHandlers finds and checks the query handlers set in this package.
+func identifyHandlers(pass *analysis.Pass) map[string][]handlers.Handler {
+	registered := map[string][]handlers.Handler{}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			// workflow.SetQueryHandler(ctx, queryType, handler)
+			if !asttools.IsFuncCall(pass.TypesInfo, call, external.WorkflowPkg,
+				external.SetQueryHandler, external.SetQueryHandlerWithOptions) {
+				return true
+			}
+			name, ok := asttools.StringValue(pass.TypesInfo, call.Args[1])
+			if !ok {
+				if debug {
+					fmt.Printf("Query type at %s is not a constant, ignoring\n", pass.Fset.Position(call.Pos()))
+				}
+				return true
+			}
+			sig, ok := pass.TypesInfo.TypeOf(call.Args[2]).Underlying().(*goTypes.Signature)
+			if !ok {
+				pass.Reportf(call.Args[2].Pos(), "Query handler `%s` must be a function", name)
+				return true
+			}
+			if checkHandler(pass, call.Args[2], name, sig) {
+				registered[name] = append(registered[name], handlers.FromSignature(sig))
+			}
+			return true
+		})
+	}
+	return registered
+}
+
+// checkHandler checks that the handler returns a serializable result and an error,
+// and that its arguments are serializable. Returns false if the handler could never be registered.
+func checkHandler(pass *analysis.Pass, handler ast.Expr, name string, sig *goTypes.Signature) bool {
+	if sig.Results().Len() != 2 || sig.Results().At(1).Type().String() != "error" {
+		pass.Reportf(handler.Pos(), "Query handler `%s` must return a serializable result and an error, "+
+			"but returns %s", name, sig.Results())
+		return false
+	}
+	result := sig.Results().At(0).Type()
+	if is, why := asttools.IsSerializable(result); !is {
+		pass.Reportf(handler.Pos(), "Query handler `%s` result (`%s`) is not serializable\n\treason: %s",
+			name, result, why)
+	}
+	for i := range sig.Params().Len() {
+		param := sig.Params().At(i)
+		if is, why := asttools.IsSerializable(param.Type()); !is {
+			pass.Reportf(handler.Pos(), "Query handler `%s` argument `%s` (`%s`) is not serializable\n\treason: %s",
+				name, param.Name(), param.Type(), why)
+		}
+	}
+	return true
+}
+
+// queryName returns the query type of a client.QueryWorkflow call, if it is one, and the type is a constant.
+func queryName(pass *analysis.Pass, call *ast.CallExpr) (string, bool) {
+	if !asttools.IsMethodCall(pass.TypesInfo, call, external.ClientType, external.QueryWorkflow) {
+		return "", false
+	}
+	if len(call.Args) < 4 {
+		return "", false
+	}
+	return asttools.StringValue(pass.TypesInfo, call.Args[3])
+}
+
+func checkQueryArgs(pass *analysis.Pass, r handlers.Resolver, call *ast.CallExpr, name string, hs []handlers.Handler) {
+	if call.Ellipsis.IsValid() {
+		// args... - we can't tell what's in there
+		return
+	}
+	// skip the context, workflow ID, run ID, and the query type
+	handlers.CheckArgs(pass, r, call.Pos(), "query", name, hs, call.Args[4:])
+}
+
+// checkResultTarget checks that EncodedValue.Get decodes the query result into a pointer to the result type
+// of one of the handlers of the query.
+func checkResultTarget(pass *analysis.Pass, r handlers.Resolver, target ast.Expr, name string, hs []handlers.Handler) {
+	ptr, ok := pass.TypesInfo.TypeOf(target).(*goTypes.Pointer)
+	if !ok {
+		pass.Reportf(target.Pos(), "Result of query `%s` must be decoded into a pointer, got %s",
+			name, pass.TypesInfo.TypeOf(target))
+		return
+	}
+	if len(hs) == 0 {
+		return
+	}
+	var expected []string
+	for _, h := range hs {
+		if asttools.TypesMatch(ptr.Elem(), r.Resolve(h.Result)) {
+			return
+		}
+		expected = append(expected, h.Result.String())
+	}
+	pass.Reportf(target.Pos(), "Type of query `%s` result does not match the type returned by its handler\n"+
+		"\tExpected: %s,\n\t     got: %s", name, strings.Join(expected, " or "), ptr.Elem())
+}
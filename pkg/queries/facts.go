@@ -0,0 +1,25 @@
+package queries
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+// queriesFact records the query handlers registered in a package, by query type,
+// so that QueryWorkflow calls in the packages importing it can be checked.
+type queriesFact struct {
+	Handlers map[string][]handlers.Handler
+}
+
+func (f *queriesFact) AFact() {}
+
+func (f *queriesFact) String() string {
+	var names []string
+	for name := range f.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("queries(%v)", names)
+}
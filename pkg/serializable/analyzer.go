@@ -7,6 +7,8 @@ import (
 	"go/token"
 	goTypes "go/types"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"golang.org/x/tools/go/analysis"
@@ -24,7 +26,8 @@ var Analyzer = &analysis.Analyzer{
 	Requires: []*analysis.Analyzer{
 		callables.Analyzer,
 	},
-	Flags: flag.FlagSet{},
+	Flags:     flag.FlagSet{},
+	FactTypes: []analysis.Fact{new(executedFact)},
 }
 
 func init() {
@@ -32,15 +35,62 @@ func init() {
 		"Enable debug mode")
 	Analyzer.Flags.BoolVar(&reportUnresolved, "report-unresolved", false,
 		"Report unresolved workflow/activity names")
-	Analyzer.Flags.BoolVar(&strictPointerMatch, "strict-pointer-match", false,
-		"Require pointer types to match exactly, otherwise pointer vs underlying type is considered a match")
+	Analyzer.Flags.BoolFunc("strict-pointer-match",
+		"Require pointer types to match exactly, otherwise pointer vs underlying type is considered a match "+
+			"(for signals, queries and updates as well)",
+		func(strict string) error {
+			isStrict, err := strconv.ParseBool(strict)
+			asttools.SetStrictPointerMatch(isStrict)
+			return err
+		})
+	Analyzer.Flags.BoolVar(&reportUnregistered, "report-unregistered", true,
+		"Report workflows and activities that are executed, but never registered (checked where they are registered)")
+	Analyzer.Flags.BoolVar(&reportTaskQueueMismatch, "report-task-queue-mismatch", true,
+		"Report workflows and activities routed to a task queue whose workers don't register them")
+	Analyzer.Flags.BoolVar(&reportUnused, "report-unused-registrations", false,
+		"Report registered workflows and activities that are never executed (checked where they are registered)")
+	Analyzer.Flags.StringVar(&externalWorkflows, "external-workflows", "",
+		"Comma-separated names of workflows started outside of the analyzed code, not reported as unused")
+	Analyzer.Flags.BoolVar(&allowContinueAsNewToOther, "allow-continue-as-new-to-other", false,
+		"Allow continue-as-new to start a different workflow than the one it's called from")
+	Analyzer.Flags.Func("payload-converters",
+		"Comma-separated chain of the payload converters of the data converter, each value is encoded by the first "+
+			"one accepting it (nil, []byte, proto-json, proto, json, gob, msgpack; default: nil,[]byte,proto-json,json)",
+		func(converters string) error {
+			return asttools.SetPayloadConverters(strings.Split(converters, ","))
+		})
+	Analyzer.Flags.Func("serializable-types",
+		"Comma-separated qualified names of types (e.g. github.com/google/uuid.UUID), or interfaces implemented by types, "+
+			"that are always serializable (e.g. by a custom payload converter)",
+		func(names string) error {
+			asttools.AddSerializableTypes(strings.Split(names, ","))
+			return nil
+		})
+	Analyzer.Flags.BoolFunc("strict-interfaces",
+		"Report interface types (including any, and error) in payloads, which are not decoded as the types that were sent",
+		func(strict string) error {
+			isStrict, err := strconv.ParseBool(strict)
+			asttools.SetStrictInterfaces(isStrict)
+			return err
+		})
+	Analyzer.Flags.BoolVar(&reportSensitiveData, "report-sensitive-data", false,
+		"Report secrets (e.g. Password, Token, SSN, or fields tagged `temporal:\"sensitive\"`) "+
+			"in workflow and activity payloads, stored in plain text in the workflow history")
+	Analyzer.Flags.BoolVar(&encryptedPayloads, "encrypted-payloads", false,
+		"The data converter encrypts payloads (e.g. with an encrypting payload codec), sensitive data is not reported")
 	pflag.CommandLine.AddGoFlagSet(&Analyzer.Flags)
 }
 
 var (
-	debug              bool
-	reportUnresolved   bool
-	strictPointerMatch bool
+	debug                     bool
+	reportUnresolved          bool
+	reportUnregistered        bool
+	reportUnused              bool
+	reportTaskQueueMismatch   bool
+	externalWorkflows         string
+	allowContinueAsNewToOther bool
+	reportSensitiveData       bool
+	encryptedPayloads         bool
 )
 
 func run(pass *analysis.Pass) (interface{}, error) {
@@ -57,51 +107,63 @@ func run(pass *analysis.Pass) (interface{}, error) {
 
 	// get all places where we call a workflow or an activity
 	calls := identifyCalls(pass)
+	precision := newPrecisionChecker(pass)
+	workflows := knownWorkflows(thisPkg, calls)
 	for _, c := range calls {
 		callee := c.Callee
-		if callee == nil {
-			caleePos := 2 // default to workflow identifier position in the call
-			if c.Type == types.Activity {
-				caleePos = 1
-			}
-			// we may not know the type, let's see if it's called by name
-			// if it's called by name, we can look it up in the package
-			// if it's not, we can't do anything
-			bLit, isBasicLit := c.Expr.Args[caleePos].(*ast.BasicLit)
-			if isBasicLit && bLit.Kind == token.STRING {
-				// we have a string literal, we can look it up
-				for _, o := range thisPkg.Workflows {
-					if o.Name() == bLit.Value {
-						callee = o
-						break
-					}
-				}
-			}
-			if callee == nil && reportUnresolved {
-				// can we make it a warning?
-				pass.Reportf(c.Pos, "Could not resolve the type of the workflow/activity")
-			}
+		if callee == nil && reportUnresolved {
+			// the user may decide to report unresolved workflow/activity names
+			// if their use-case should always point to package-local functions
+			// (can we make it a warning?)
+			pass.Reportf(c.Pos, "Could not resolve the type of the workflow/activity")
 		}
-		for _, callArg := range c.CallArgs {
+		for i, callArg := range c.CallArgs {
 			actualT := pass.TypesInfo.TypeOf(callArg)
-			if actualT != nil {
+			if actualT != nil && !checkedAtDeclaration(thisPkg, c, i, actualT) {
 				// get argument name from callArg
 				// if it's a struct, check if all fields are exported
 				checkArgType(pass, c, actualT, callArg)
 			}
 		}
 
+		precision.check(c)
+
+		if structType, ok := thisPkg.UnregisteredMethods[callee]; ok {
+			pass.Reportf(c.Pos, "`%s` has a pointer receiver, but `%s` is registered as a value: "+
+				"the activity will not be registered", callee.Name(), structType)
+		}
+
 		// additionally, check if the type of the argument matches the argument type of the workflow/activity
+		var signature *goTypes.Signature
 		if callee != nil {
-			signature := callee.Type().(*goTypes.Signature)
+			signature, _ = callee.Type().(*goTypes.Signature)
+		}
+		if signature != nil {
 			checkArgumentCount(pass, c.Pos, callee.Name(), signature, c.CallArgs)
 			checkArgumentTypes(pass, c.Pos, callee.Name(), signature, c.CallArgs)
+			if isContinueAsNew(c) && !allowContinueAsNewToOther {
+				checkContinueAsNewTarget(pass, c, callee, workflows)
+			}
 
 			if debug {
 				fmt.Printf("Call to %s at %s\n", c.Callee.Name(), pass.Fset.Position(c.Pos))
 			}
 		}
 	}
+	checkResults(pass, calls)
+	exportExecuted(pass, calls)
+	if reportUnregistered {
+		checkRegistered(pass, thisPkg, calls)
+	}
+	if reportTaskQueueMismatch {
+		checkTaskQueues(pass, thisPkg, calls)
+	}
+	if reportUnused {
+		checkUnused(pass, thisPkg, calls)
+	}
+	if reportSensitiveData && !encryptedPayloads {
+		checkSensitive(pass, thisPkg, calls)
+	}
 	if debug {
 		fmt.Printf("%d calls to workflows/activities checked\n", len(calls))
 	}
@@ -126,6 +188,97 @@ func checkArgType(pass *analysis.Pass, c types.TemporalCall, actualT goTypes.Typ
 	}
 }
 
+// checkedAtDeclaration returns true if the argument is passed as the type of the parameter of a registered
+// workflow/activity: the callables analyzer checks these at the declaration, so the call site is not reported again.
+func checkedAtDeclaration(thisPkg callables.Callables, c types.TemporalCall, argIdx int, actualT goTypes.Type) bool {
+	if c.Callee == nil || !thisPkg.Registry.IsRegistered(c.Type, c.Callee) {
+		return false
+	}
+	signature, ok := c.Callee.Type().(*goTypes.Signature)
+	if !ok {
+		return false
+	}
+	expectedT := paramType(signature, argIdx)
+	if expectedT == nil {
+		return false
+	}
+	if ptr, ok := expectedT.(*goTypes.Pointer); ok {
+		expectedT = ptr.Elem()
+	}
+	if ptr, ok := actualT.(*goTypes.Pointer); ok {
+		actualT = ptr.Elem()
+	}
+	return goTypes.Identical(expectedT, actualT)
+}
+
+// paramType returns the type of the parameter the call argument at argIdx is passed to (the parameters start
+// with a context, that is not included in the call arguments), the element type for variadic ones,
+// or nil if there's no such parameter.
+func paramType(signature *goTypes.Signature, argIdx int) goTypes.Type {
+	params := signature.Params()
+	idx := argIdx + 1
+	if signature.Variadic() && idx >= params.Len()-1 {
+		return params.At(params.Len() - 1).Type().(*goTypes.Slice).Elem()
+	}
+	if idx < params.Len() {
+		return params.At(idx).Type()
+	}
+	return nil
+}
+
+// knownWorkflows returns the workflows registered in this package, and the ones started (or continued as new) by the calls.
+func knownWorkflows(thisPkg callables.Callables, calls []types.TemporalCall) map[goTypes.Object]bool {
+	workflows := map[goTypes.Object]bool{}
+	for _, w := range thisPkg.Workflows {
+		workflows[w] = true
+	}
+	for _, c := range calls {
+		if c.Type == types.Workflow && c.Callee != nil {
+			workflows[c.Callee] = true
+		}
+	}
+	return workflows
+}
+
+// checkContinueAsNewTarget reports continue-as-new calls that start a different workflow
+// than the one they are called from. Calls from other functions than known workflows (e.g. helpers
+// building the continue-as-new error for several workflows) are not checked.
+func checkContinueAsNewTarget(pass *analysis.Pass, c types.TemporalCall, callee goTypes.Object,
+	workflows map[goTypes.Object]bool,
+) {
+	caller := enclosingFunc(pass, c.Pos)
+	if caller == nil {
+		return
+	}
+	callerObj := pass.TypesInfo.ObjectOf(caller.Name)
+	if callerObj == callee || caller.Name.Name == callee.Name() {
+		return
+	}
+	if !workflows[callerObj] {
+		return
+	}
+	pass.Reportf(c.Pos, "Continue-as-new starts `%s`, not `%s` it is called from", callee.Name(), caller.Name.Name)
+}
+
+func isContinueAsNew(c types.TemporalCall) bool {
+	return c.CallName == external.NewContinueAsNewError || c.CallName == external.NewContinueAsNewErrorWithOptions
+}
+
+// enclosingFunc returns the declaration of the function containing pos.
+func enclosingFunc(pass *analysis.Pass, pos token.Pos) *ast.FuncDecl {
+	for _, f := range pass.Files {
+		if pos < f.Pos() || pos >= f.End() {
+			continue
+		}
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Pos() <= pos && pos < fd.End() {
+				return fd
+			}
+		}
+	}
+	return nil
+}
+
 func checkArgumentTypes(pass *analysis.Pass, pos token.Pos, callee string, signature *goTypes.Signature, callArgs []ast.Expr) {
 	expectedParams := signature.Params().Len() - 1
 	// we are going to check up to the maximum of expected and actual arguments
@@ -140,41 +293,15 @@ func checkArgumentTypes(pass *analysis.Pass, pos token.Pos, callee string, signa
 		// - expected args start with a context, that is not included in the call arguments
 		// - for variadic functions, we need to compare the type of the variadic parameter ([]T)
 		//   with the type of all trailing arguments (T)
-		if signature.Variadic() && argIdx >= expectedParams-1 {
-			expectedT = signature.Params().At(signature.Params().Len() - 1).Type().(*goTypes.Slice).Elem()
-		} else {
-			if argIdx < signature.Params().Len()-1 {
-				expectedT = signature.Params().At(argIdx + 1).Type()
-			}
-		}
+		expectedT = paramType(signature, argIdx)
 		if argIdx < len(callArgs) {
 			actualT = pass.TypesInfo.TypeOf(callArgs[argIdx])
 		}
 		if expectedT == nil || actualT == nil {
 			continue
 		}
-		if !goTypes.Identical(expectedT, actualT) {
-			// is it a pointer vs non-pointer mismatch? (temporal handles these)
-			if !strictPointerMatch {
-				if ptr, ok := expectedT.(*goTypes.Pointer); ok {
-					if goTypes.Identical(ptr.Elem(), actualT) {
-						continue
-					}
-				}
-				if ptr, ok := actualT.(*goTypes.Pointer); ok {
-					if goTypes.Identical(ptr.Elem(), expectedT) {
-						continue
-					}
-				}
-				// and if expected type is a struct or a pointer to a struct, and actual is untyped nil, it's fine
-				if _, ok := expectedT.Underlying().(*goTypes.Struct); ok {
-					if actualT == goTypes.Typ[goTypes.UntypedNil] {
-						continue
-					}
-				}
-			}
-
-			ordinal := numberToOrdinal(argIdx + 1)
+		if !asttools.TypesMatch(expectedT, actualT) {
+			ordinal := asttools.NumberToOrdinal(argIdx + 1)
 			pass.Reportf(pos, "Type of %s argument to `%s` does not match the type of the workflow/activity\n"+
 				"\tExpected: %s,\n\t     got: %s", ordinal, callee, expectedT, actualT)
 		}
@@ -217,7 +344,25 @@ func identifyCalls(pass *analysis.Pass) []types.TemporalCall {
 			if !ok {
 				return true
 			}
-			if selector.Sel.Name != external.ExecuteWorkflow && selector.Sel.Name != external.ExecuteActivity {
+			var calleeIdx int
+			var callType types.TemporalIoCallType
+			switch selector.Sel.Name {
+			case external.ExecuteWorkflow:
+				calleeIdx, callType = 2, types.Workflow
+			case external.ExecuteActivity:
+				calleeIdx, callType = 1, types.Activity
+			case external.ExecuteChildWorkflow:
+				calleeIdx, callType = 1, types.Workflow
+			// client.SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, MyWorkflow, args...)
+			case external.SignalWithStartWorkflow:
+				calleeIdx, callType = 5, types.Workflow
+			// continuing as new is effectively starting a workflow:
+			// workflow.NewContinueAsNewError(ctx, MyWorkflow, args...)
+			case external.NewContinueAsNewError:
+				calleeIdx, callType = 1, types.Workflow
+			case external.NewContinueAsNewErrorWithOptions:
+				calleeIdx, callType = 2, types.Workflow
+			default:
 				return true
 			}
 			x, ok := selector.X.(*ast.Ident)
@@ -238,18 +383,21 @@ func identifyCalls(pass *analysis.Pass) []types.TemporalCall {
 			}
 			xType := pass.TypesInfo.TypeOf(x)
 			if xType != nil && xType.String() == external.ClientType {
-				callee := call.Args[2]
-				calleeID := asttools.IdentifierOf(callee)
-				caleeObj := pass.TypesInfo.ObjectOf(calleeID)
+				if len(call.Args) <= calleeIdx {
+					return true
+				}
+				callee := call.Args[calleeIdx]
+				caleeObj := resolveCallee(pass, types.Workflow, callee)
 
 				calls = append(calls, types.TemporalCall{
-					Pos:      call.Pos(),
-					FileName: pass.Fset.Position(call.Pos()).Filename,
-					CallName: selector.Sel.Name,
-					Expr:     call,
-					Callee:   caleeObj,
-					// skip the first two arguments (context, start options, and the callee)
-					CallArgs: call.Args[3:],
+					Pos:        call.Pos(),
+					FileName:   pass.Fset.Position(call.Pos()).Filename,
+					CallName:   selector.Sel.Name,
+					Expr:       call,
+					CalleeExpr: callee,
+					Callee:     caleeObj,
+					// skip the context, start options (and signal, if any), and the callee
+					CallArgs: call.Args[calleeIdx+1:],
 					Type:     types.Workflow,
 				})
 				return true
@@ -271,67 +419,94 @@ func identifyCalls(pass *analysis.Pass) []types.TemporalCall {
 			}
 			// check if the package name is "go.temporal.io/sdk/workflow"
 			if p.Imported().Path() == external.WorkflowPkg {
-				callee := call.Args[1]
-				var calleeID *ast.Ident
-				var caleeObj goTypes.Object
-				// if we expect the calee to be identified by a string literal
-				if pass.TypesInfo.TypeOf(callee).String() == "string" {
-					// if we have a string literal, we can look it up
-					if lit, ok := callee.(*ast.BasicLit); ok && lit.Kind == token.STRING {
-						litValue := lit.Value
-						for _, o := range pass.ResultOf[callables.Analyzer].(callables.Callables).Workflows {
-							if o.Name() == litValue {
-								caleeObj = o
-								break
-							}
-						}
-					}
-					if caleeObj == nil {
-						// the user may decide to report unresolved workflow/activity names
-						// if their use-case should always point to package-local functions
-						if reportUnresolved {
-							// can we make it a warning?
-							pass.Reportf(call.Pos(), "Could not resolve the type of the workflow/activity")
-						}
-						return true // not much we can do
-					}
+				if len(call.Args) <= calleeIdx {
+					return true
 				}
-				calleeID = asttools.IdentifierOf(callee)
-				caleeObj = pass.TypesInfo.ObjectOf(calleeID)
+				callee := call.Args[calleeIdx]
+				caleeObj := resolveCallee(pass, callType, callee)
 
 				calls = append(calls, types.TemporalCall{
-					Pos:      call.Pos(),
-					FileName: pass.Fset.Position(call.Pos()).Filename,
-					CallName: selector.Sel.Name,
-					Expr:     call,
-					Callee:   caleeObj,
-					// skip the first two arguments (context, and the callee)
-					CallArgs: call.Args[2:],
-					Type:     types.Activity,
+					Pos:        call.Pos(),
+					FileName:   pass.Fset.Position(call.Pos()).Filename,
+					CallName:   selector.Sel.Name,
+					Expr:       call,
+					CalleeExpr: callee,
+					Callee:     caleeObj,
+					// skip the context (and options, if any), and the callee
+					CallArgs: call.Args[calleeIdx+1:],
+					Type:     callType,
 				})
 			}
 
 			return true
 		})
 	}
+	calls = append(calls, identifyScheduleActions(pass)...)
+	routeCalls(pass, calls)
+	return calls
+}
+
+// identifyScheduleActions finds the workflows started by schedules:
+// client.ScheduleWorkflowAction{Workflow: MyWorkflow, Args: []interface{}{"World"}}
+// Actions with arguments other than a slice literal are skipped, as we cannot check them.
+func identifyScheduleActions(pass *analysis.Pass) []types.TemporalCall {
+	var calls []types.TemporalCall
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			t := pass.TypesInfo.TypeOf(lit)
+			if t == nil || goTypes.Unalias(t).String() != external.ScheduleWorkflowActionType {
+				return true
+			}
+			callee := asttools.FieldValue(lit, "Workflow")
+			if callee == nil {
+				return true
+			}
+			var args []ast.Expr
+			if argsExpr := asttools.FieldValue(lit, "Args"); argsExpr != nil {
+				argsLit, ok := argsExpr.(*ast.CompositeLit)
+				if !ok {
+					return true
+				}
+				args = argsLit.Elts
+			}
+			calls = append(calls, types.TemporalCall{
+				Pos:        lit.Pos(),
+				FileName:   pass.Fset.Position(lit.Pos()).Filename,
+				CallName:   "ScheduleWorkflowAction",
+				CalleeExpr: callee,
+				Callee:     resolveCallee(pass, types.Workflow, callee),
+				CallArgs:   args,
+				Type:       types.Workflow,
+				TaskQueue:  taskQueue(pass, lit),
+			})
+			return true
+		})
+	}
 	return calls
 }
 
-func numberToOrdinal(n int) string {
-	if n <= 0 {
-		return "0"
-	}
-	if n%100 >= 11 && n%100 <= 13 {
-		return fmt.Sprintf("%dth", n)
-	}
-	switch n % 10 {
-	case 1:
-		return fmt.Sprintf("%dst", n)
-	case 2:
-		return fmt.Sprintf("%dnd", n)
-	case 3:
-		return fmt.Sprintf("%drd", n)
-	default:
-		return fmt.Sprintf("%dth", n)
+// resolveCallee returns the workflow/activity the expression refers to: a function or a method value,
+// or the name it is registered under.
+func resolveCallee(pass *analysis.Pass, callType types.TemporalIoCallType, callee ast.Expr) goTypes.Object {
+	if name, ok := asttools.StringValue(pass.TypesInfo, callee); ok {
+		return pass.ResultOf[callables.Analyzer].(callables.Callables).Registry.Lookup(callType, name)
+	}
+	// a name that is not a constant (e.g. a variable, or the result of a function) cannot be resolved
+	if t := pass.TypesInfo.TypeOf(callee); t != nil {
+		if basic, ok := t.Underlying().(*goTypes.Basic); ok && basic.Info()&goTypes.IsString != 0 {
+			return nil
+		}
+	}
+	o := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(callee))
+	if o == nil {
+		return nil
+	}
+	if _, ok := o.Type().(*goTypes.Signature); !ok {
+		return nil
 	}
+	return o
 }
@@ -0,0 +1,141 @@
+package serializable
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	goTypes "go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// precisionChecker reports int64 and uint64 values passed to workflows and activities in interface values:
+// the decoder has no type to decode JSON numbers into but float64, which loses precision above 2^53.
+// The other encodings (e.g. gob) keep the types of the numbers, so only JSON payloads are checked.
+type precisionChecker struct {
+	pass   *analysis.Pass
+	values asttools.Values
+	// indexed are the values assigned to the elements of variables, e.g. payload["id"] = id
+	// (like Values, control flow is not taken into account: the ones before the call are in the payload)
+	indexed map[goTypes.Object][]ast.Expr
+}
+
+func newPrecisionChecker(pass *analysis.Pass) *precisionChecker {
+	p := &precisionChecker{
+		pass:    pass,
+		values:  asttools.AssignedValues(pass.TypesInfo, pass.Files),
+		indexed: map[goTypes.Object][]ast.Expr{},
+	}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			assign, ok := n.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != len(assign.Rhs) {
+				return true
+			}
+			for i, lhs := range assign.Lhs {
+				index, ok := lhs.(*ast.IndexExpr)
+				if !ok {
+					continue
+				}
+				if ident, ok := ast.Unparen(index.X).(*ast.Ident); ok {
+					if o := pass.TypesInfo.ObjectOf(ident); o != nil {
+						p.indexed[o] = append(p.indexed[o], assign.Rhs[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return p
+}
+
+// check reports the large integers in the arguments of the call that are decoded into interface values:
+// passed as interface parameters, or placed into interface containers, e.g. map[string]any{"id": id}.
+func (p *precisionChecker) check(c types.TemporalCall) {
+	var signature *goTypes.Signature
+	if c.Callee != nil {
+		signature, _ = c.Callee.Type().(*goTypes.Signature)
+	}
+	for i, arg := range c.CallArgs {
+		container := p.pass.TypesInfo.TypeOf(arg)
+		if signature != nil {
+			if expected := paramType(signature, i); expected != nil && asttools.IsInterfaceContainer(expected) {
+				container = expected
+			}
+		}
+		if container != nil && asttools.IsInterfaceContainer(container) && asttools.IsJSONPayload(p.pass.TypesInfo.TypeOf(arg)) {
+			p.inspect(arg, container, c.Pos, map[ast.Expr]bool{})
+		}
+	}
+}
+
+// inspect reports the large integers in the expression placed into an interface value of the container type,
+// by the call at pos.
+func (p *precisionChecker) inspect(e ast.Expr, container goTypes.Type, pos token.Pos, seen map[ast.Expr]bool) {
+	if seen[e] {
+		return
+	}
+	seen[e] = true
+
+	t := p.pass.TypesInfo.TypeOf(e)
+	if t == nil {
+		return
+	}
+	if !asttools.IsInterfaceContainer(t) {
+		if !isExactConstant(p.pass, e) {
+			p.report(e, t, container)
+		}
+		return
+	}
+	// an interface container itself: look at what's placed into it
+	if lit, ok := ast.Unparen(p.values.At(e)).(*ast.CompositeLit); ok {
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				elt = kv.Value
+			}
+			p.inspect(elt, t, pos, seen)
+		}
+	}
+	if ident, ok := ast.Unparen(e).(*ast.Ident); ok {
+		for _, v := range p.indexed[p.pass.TypesInfo.ObjectOf(ident)] {
+			if v.Pos() < pos {
+				p.inspect(v, t, pos, seen)
+			}
+		}
+	}
+}
+
+func (p *precisionChecker) report(e ast.Expr, t, container goTypes.Type) {
+	positions := asttools.LargeIntegers(t)
+	if len(positions) == 0 {
+		return
+	}
+	var fields []string
+	for _, position := range positions {
+		if position != "" {
+			fields = append(fields, position)
+		}
+	}
+	what := "it's"
+	if len(fields) > 0 {
+		what = "its int64/uint64 values (at `" + strings.Join(fields, "`, `") + "`) are"
+	}
+	p.pass.Reportf(e.Pos(), "`%s` (`%s`) is passed in an interface value (`%s`): %s decoded as float64, "+
+		"losing precision above 2^53. Encode large integers as strings (e.g. `json:\",string\"`), or pass a typed value",
+		goTypes.ExprString(e), goTypes.TypeString(t, goTypes.RelativeTo(p.pass.Pkg)),
+		goTypes.TypeString(container, goTypes.RelativeTo(p.pass.Pkg)), what)
+}
+
+// isExactConstant returns true for the constants float64 represents exactly.
+func isExactConstant(pass *analysis.Pass, e ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[e]
+	if !ok || tv.Value == nil {
+		return false
+	}
+	v, exact := constant.Int64Val(constant.ToInt(tv.Value))
+	return exact && v > -(1<<53) && v < 1<<53
+}
@@ -0,0 +1,199 @@
+package serializable
+
+import (
+	"fmt"
+	goTypes "go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/callables"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// executedFact records the workflows and activities executed in a package,
+// so that the packages registering them (usually the worker, importing the workflows) can check
+// that everything that is executed is registered.
+type executedFact struct {
+	Calls []executed
+}
+
+func (e *executedFact) AFact() {}
+
+func (e *executedFact) String() string {
+	return fmt.Sprintf("executed(%d calls)", len(e.Calls))
+}
+
+// executed is a workflow or an activity executed at Position, referred to either by the Name it's registered under,
+// or as the function identified by its package and object path.
+type executed struct {
+	Type     types.TemporalIoCallType
+	Name     string
+	Pkg      string
+	Path     objectpath.Path
+	Callee   string
+	Position string
+	// TaskQueue is the task queue the call is routed to, empty if it's not known
+	TaskQueue string
+}
+
+// exportExecuted exports the calls to workflows and activities of this package as a package fact.
+func exportExecuted(pass *analysis.Pass, calls []types.TemporalCall) {
+	var fact executedFact
+	for _, c := range calls {
+		e := executed{
+			Type:      c.Type,
+			Position:  pass.Fset.Position(c.Pos).String(),
+			TaskQueue: c.TaskQueue,
+		}
+		if name, ok := calleeName(pass, c); ok {
+			e.Name, e.Callee = name, name
+		} else if c.Callee != nil && c.Callee.Pkg() != nil {
+			path, err := objectpath.For(c.Callee)
+			if err != nil {
+				continue
+			}
+			e.Pkg, e.Path, e.Callee = c.Callee.Pkg().Path(), path, c.Callee.Name()
+		} else {
+			continue
+		}
+		fact.Calls = append(fact.Calls, e)
+	}
+	if len(fact.Calls) > 0 {
+		pass.ExportPackageFact(&fact)
+	}
+}
+
+// checkRegistered reports the workflows and activities executed in this package, or in the packages it imports,
+// that are not registered in this package, or in the packages it imports.
+// It only runs in packages that create their workers and register all their workflows and activities themselves,
+// as it needs to see all the registrations of the worker to make sense.
+// Calls routed to task queues that are not served by these workers (see routeCalls) are left to the other workers.
+func checkRegistered(pass *analysis.Pass, thisPkg callables.Callables, calls []types.TemporalCall) {
+	if len(thisPkg.Registrations) == 0 || !thisPkg.OwnsWorkers {
+		return
+	}
+	for _, c := range calls {
+		if _, reported := thisPkg.UnregisteredMethods[c.Callee]; reported || !isServed(thisPkg, c.TaskQueue) {
+			continue
+		}
+		if name, ok := calleeName(pass, c); ok {
+			if c.Callee == nil {
+				pass.Reportf(c.Pos, "%s `%s` is executed, but never registered", kindOf(c.Type), name)
+			}
+			continue
+		}
+		if c.Callee != nil && !thisPkg.Registry.IsRegistered(c.Type, c.Callee) {
+			pass.Reportf(c.Pos, "%s `%s` is executed, but never registered", kindOf(c.Type), c.Callee.Name())
+		}
+	}
+
+	// calls in the imported packages are reported once per callee, at the first registration
+	packages := asttools.ImportedPackages(pass.Pkg)
+	reported := map[string]bool{}
+	for _, f := range pass.AllPackageFacts() {
+		fact, ok := f.Fact.(*executedFact)
+		if !ok || f.Package == pass.Pkg {
+			continue
+		}
+		for _, e := range fact.Calls {
+			if !isServed(thisPkg, e.TaskQueue) {
+				continue
+			}
+			// if we can't resolve the callee, we can't tell
+			isRegistered := true
+			if e.Name != "" {
+				isRegistered = thisPkg.Registry.Lookup(e.Type, e.Name) != nil
+			} else if pkg, ok := packages[e.Pkg]; ok {
+				if o, err := objectpath.Object(pkg, e.Path); err == nil {
+					isRegistered = thisPkg.Registry.IsRegistered(e.Type, o)
+				}
+			}
+			key := fmt.Sprint(e.Type, e.Name, e.Pkg, e.Path)
+			if isRegistered || reported[key] {
+				continue
+			}
+			reported[key] = true
+			pass.Reportf(thisPkg.Registrations[0].Call.Pos(), "%s `%s` is executed at %s, but never registered",
+				kindOf(e.Type), e.Callee, e.Position)
+		}
+	}
+}
+
+// isServed returns true if the calls routed to the task queue are served by the workers of this package:
+// the task queue is not known, or these workers are created for it.
+func isServed(thisPkg callables.Callables, queue string) bool {
+	if queue == "" {
+		return true
+	}
+	_, ok := thisPkg.Registry.TaskQueue(queue)
+	return ok
+}
+
+// checkUnused reports the workflows and activities registered in this package that are never executed
+// in this package, or in the packages it imports. Workflows listed in -external-workflows
+// (by the name they are registered under, or by their function name) are started elsewhere, and are not reported.
+func checkUnused(pass *analysis.Pass, thisPkg callables.Callables, calls []types.TemporalCall) {
+	if len(thisPkg.Registrations) == 0 {
+		return
+	}
+	executedObjs := map[goTypes.Object]bool{}
+	for _, c := range calls {
+		if c.Callee != nil {
+			executedObjs[c.Callee] = true
+		}
+	}
+	packages := asttools.ImportedPackages(pass.Pkg)
+	for _, f := range pass.AllPackageFacts() {
+		fact, ok := f.Fact.(*executedFact)
+		if !ok || f.Package == pass.Pkg {
+			continue
+		}
+		for _, e := range fact.Calls {
+			if e.Name != "" {
+				if o := thisPkg.Registry.Lookup(e.Type, e.Name); o != nil {
+					executedObjs[o] = true
+				}
+			} else if pkg, ok := packages[e.Pkg]; ok {
+				if o, err := objectpath.Object(pkg, e.Path); err == nil {
+					executedObjs[o] = true
+				}
+			}
+		}
+	}
+
+	startedElsewhere := map[string]bool{}
+	for _, name := range strings.Split(externalWorkflows, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			startedElsewhere[name] = true
+		}
+	}
+
+	for _, r := range thisPkg.Registrations {
+		if r.Callee == nil || executedObjs[r.Callee] {
+			continue
+		}
+		if r.Type == types.Workflow && (startedElsewhere[r.Name] || startedElsewhere[r.Callee.Name()]) {
+			continue
+		}
+		name := r.Name
+		if name == "" {
+			name = r.Callee.Name()
+		}
+		pass.Reportf(r.Call.Pos(), "%s `%s` is registered, but never executed", kindOf(r.Type), name)
+	}
+}
+
+// calleeName returns the name the callee is referred to by, if it's a name.
+func calleeName(pass *analysis.Pass, c types.TemporalCall) (string, bool) {
+	return asttools.StringValue(pass.TypesInfo, c.CalleeExpr)
+}
+
+func kindOf(t types.TemporalIoCallType) string {
+	if t == types.Activity {
+		return "Activity"
+	}
+	return "Workflow"
+}
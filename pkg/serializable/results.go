@@ -0,0 +1,74 @@
+package serializable
+
+import (
+	"go/ast"
+	goTypes "go/types"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// checkResults follows the futures (and workflow runs) returned by the calls to their .Get(ctx, &result),
+// and checks that the result is decoded into a pointer to the type the workflow/activity returns.
+func checkResults(pass *analysis.Pass, calls []types.TemporalCall) {
+	byExpr := map[*ast.CallExpr]types.TemporalCall{}
+	for _, c := range calls {
+		if c.Callee != nil && c.Expr != nil {
+			byExpr[c.Expr] = c
+		}
+	}
+	// futures held in variables, e.g. future := workflow.ExecuteActivity(ctx, act.Greet, name)
+	values := asttools.AssignedValues(pass.TypesInfo, pass.Files)
+
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			get, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			selector, ok := get.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != external.Get || len(get.Args) != 2 {
+				return true
+			}
+			call, ok := ast.Unparen(values.At(selector.X)).(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if c, ok := byExpr[call]; ok {
+				checkResultTarget(pass, c, get.Args[1])
+			}
+			return true
+		})
+	}
+}
+
+func checkResultTarget(pass *analysis.Pass, c types.TemporalCall, target ast.Expr) {
+	targetT := pass.TypesInfo.TypeOf(target)
+	if targetT == nil || targetT == goTypes.Typ[goTypes.UntypedNil] {
+		// the result is ignored
+		return
+	}
+	ptr, ok := targetT.(*goTypes.Pointer)
+	if !ok {
+		pass.Reportf(target.Pos(), "Result of `%s` must be decoded into a pointer, got %s", c.Callee.Name(), targetT)
+		return
+	}
+	signature, ok := c.Callee.Type().(*goTypes.Signature)
+	if !ok {
+		return
+	}
+	if signature.Results().Len() < 2 {
+		pass.Reportf(target.Pos(), "`%s` does not return a result, but it is decoded into %s", c.Callee.Name(), targetT)
+		return
+	}
+	// the result is decoded into the target
+	expectedT, actualT := signature.Results().At(0).Type(), ptr.Elem()
+	if asttools.TypesMatch(actualT, expectedT) {
+		return
+	}
+	pass.Reportf(target.Pos(), "Type of `%s` result does not match the type of the workflow/activity\n"+
+		"\tExpected: %s,\n\t     got: %s", c.Callee.Name(), expectedT, actualT)
+}
@@ -0,0 +1,72 @@
+package serializable
+
+import (
+	"go/token"
+	goTypes "go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/callables"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// checkSensitive reports the secrets (e.g. Password, Token, or `temporal:"sensitive"` fields) in the parameters
+// and results of the registered workflows and activities, and in the arguments of the calls to the ones registered
+// elsewhere: payloads are stored in the workflow history, in plain text unless the data converter encrypts them.
+func checkSensitive(pass *analysis.Pass, thisPkg callables.Callables, calls []types.TemporalCall) {
+	checked := map[goTypes.Object]bool{}
+	for _, r := range thisPkg.Registrations {
+		fn, ok := r.Callee.(*goTypes.Func)
+		if !ok || checked[fn] || r.Type == types.NotSupported {
+			continue
+		}
+		checked[fn] = true
+
+		pos := fn.Pos()
+		if fn.Pkg() != pass.Pkg {
+			pos = r.Call.Pos()
+		}
+		signature := fn.Signature()
+		params := signature.Params()
+		for i := range params.Len() {
+			param := params.At(i)
+			t := param.Type()
+			if i == 0 && (external.WorkflowCtx.MatchString(t.String()) || t.String() == external.ActivityCtx) {
+				continue
+			}
+			paramPos := pos
+			if fn.Pkg() == pass.Pkg {
+				paramPos = param.Pos()
+			}
+			reportSensitive(pass, paramPos, "Parameter `"+param.Name()+"` of `"+fn.Name()+"`", t)
+		}
+		if results := signature.Results(); results.Len() == 2 {
+			reportSensitive(pass, pos, "The result of `"+fn.Name()+"`", results.At(0).Type())
+		}
+	}
+
+	for _, c := range calls {
+		if c.Callee != nil && thisPkg.Registry.IsRegistered(c.Type, c.Callee) {
+			// checked at the declaration
+			continue
+		}
+		for _, arg := range c.CallArgs {
+			if t := pass.TypesInfo.TypeOf(arg); t != nil {
+				reportSensitive(pass, arg.Pos(), "Call argument `"+goTypes.ExprString(arg)+"`", t)
+			}
+		}
+	}
+}
+
+func reportSensitive(pass *analysis.Pass, pos token.Pos, what string, t goTypes.Type) {
+	fields := asttools.SensitiveFields(t)
+	if len(fields) == 0 {
+		return
+	}
+	pass.Reportf(pos, "%s (`%s`) holds sensitive data (`%s`), stored in plain text in the workflow history: "+
+		"encrypt payloads with a payload codec, or don't pass secrets to workflows and activities",
+		what, goTypes.TypeString(t, goTypes.RelativeTo(pass.Pkg)), strings.Join(fields, "`, `"))
+}
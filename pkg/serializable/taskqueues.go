@@ -0,0 +1,124 @@
+package serializable
+
+import (
+	"fmt"
+	"go/ast"
+	goTypes "go/types"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/callables"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/types"
+)
+
+// routeCalls sets the task queues the calls are routed to by their options, when they are constant:
+//   - client.ExecuteWorkflow(ctx, client.StartWorkflowOptions{TaskQueue: "queue"}, MyWorkflow)
+//   - workflow.ExecuteActivity(workflow.WithActivityOptions(ctx, workflow.ActivityOptions{TaskQueue: "queue"}), ...)
+//   - workflow.ExecuteChildWorkflow(workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{TaskQueue: "queue"}), ...)
+//
+// The options and contexts can be held in variables.
+func routeCalls(pass *analysis.Pass, calls []types.TemporalCall) {
+	values := asttools.AssignedValues(pass.TypesInfo, pass.Files)
+	for i, c := range calls {
+		if c.Expr == nil {
+			continue
+		}
+		var options ast.Expr
+		switch c.CallName {
+		case external.ExecuteWorkflow, external.SignalWithStartWorkflow:
+			// the start options are right before the workflow
+			if idx := slices.Index(c.Expr.Args, c.CalleeExpr); idx > 0 {
+				options = c.Expr.Args[idx-1]
+			}
+		case external.ExecuteActivity, external.ExecuteChildWorkflow:
+			with := external.WithActivityOptions
+			if c.CallName == external.ExecuteChildWorkflow {
+				with = external.WithChildOptions
+			}
+			ctx, ok := values.At(c.Expr.Args[0]).(*ast.CallExpr)
+			if ok && len(ctx.Args) == 2 && asttools.IsFuncCall(pass.TypesInfo, ctx, external.WorkflowPkg, with) {
+				options = ctx.Args[1]
+			}
+		}
+		if options != nil {
+			calls[i].TaskQueue = taskQueue(pass, values.At(options))
+		}
+	}
+}
+
+// taskQueue returns the constant TaskQueue set by the options literal, or an empty string.
+func taskQueue(pass *analysis.Pass, options ast.Expr) string {
+	queue, _ := asttools.StringValue(pass.TypesInfo, asttools.FieldValue(options, "TaskQueue"))
+	return queue
+}
+
+// checkTaskQueues reports the workflows and activities routed to a task queue whose workers don't register them.
+// Like checkRegistered, it runs in the packages registering workflows and activities, for the calls in them
+// and in the packages they import, and only for the task queues whose workers' registrations are all visible.
+func checkTaskQueues(pass *analysis.Pass, thisPkg callables.Callables, calls []types.TemporalCall) {
+	if len(thisPkg.Registrations) == 0 {
+		return
+	}
+	for _, c := range calls {
+		if c.TaskQueue == "" || c.Callee == nil {
+			continue
+		}
+		queue, ok := thisPkg.Registry.TaskQueue(c.TaskQueue)
+		if !ok {
+			continue
+		}
+		name, byName := calleeName(pass, c)
+		if !isRegisteredOn(queue, c.Type, name, byName, c.Callee) {
+			if !byName {
+				name = c.Callee.Name()
+			}
+			pass.Reportf(c.Pos, "%s `%s` is routed to task queue `%s`, but its workers don't register it",
+				kindOf(c.Type), name, c.TaskQueue)
+		}
+	}
+
+	packages := asttools.ImportedPackages(pass.Pkg)
+	reported := map[string]bool{}
+	for _, f := range pass.AllPackageFacts() {
+		fact, ok := f.Fact.(*executedFact)
+		if !ok || f.Package == pass.Pkg {
+			continue
+		}
+		for _, e := range fact.Calls {
+			if e.TaskQueue == "" {
+				continue
+			}
+			queue, ok := thisPkg.Registry.TaskQueue(e.TaskQueue)
+			if !ok {
+				continue
+			}
+			var o goTypes.Object
+			if pkg, ok := packages[e.Pkg]; ok && e.Name == "" {
+				if o, _ = objectpath.Object(pkg, e.Path); o == nil {
+					continue
+				}
+			}
+			key := fmt.Sprint(e.Type, e.Name, e.Pkg, e.Path, e.TaskQueue)
+			if isRegisteredOn(queue, e.Type, e.Name, e.Name != "", o) || reported[key] {
+				continue
+			}
+			reported[key] = true
+			pass.Reportf(thisPkg.Registrations[0].Call.Pos(),
+				"%s `%s` is routed to task queue `%s` at %s, but its workers don't register it",
+				kindOf(e.Type), e.Callee, e.TaskQueue, e.Position)
+		}
+	}
+}
+
+// isRegisteredOn returns true if the workflow/activity, called by name or by its function, is registered in the registry.
+// A function that can't be resolved is assumed to be registered.
+func isRegisteredOn(registry callables.Registry, t types.TemporalIoCallType, name string, byName bool, o goTypes.Object) bool {
+	if byName {
+		return registry.Lookup(t, name) != nil
+	}
+	return o == nil || registry.IsRegistered(t, o)
+}
@@ -0,0 +1,267 @@
+package signals
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	goTypes "go/types"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "TemporalioSignals",
+	Doc:       "Checks that signals sent to Temporal.io workflows match, by name and payload type, the signals workflows receive.",
+	Run:       run,
+	Flags:     flag.FlagSet{},
+	FactTypes: []analysis.Fact{new(signalsFact)},
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&debug, "debug-signals", false,
+		"Enable debug mode")
+	Analyzer.Flags.BoolVar(&reportUnmatched, "report-unmatched-signals", true,
+		"Report signals that are sent but never received")
+	Analyzer.Flags.BoolVar(&reportUnsent, "report-unsent-signals", false,
+		"Report signals that are received but never sent (the senders are often in packages the workflows don't import)")
+	pflag.CommandLine.AddGoFlagSet(&Analyzer.Flags)
+}
+
+var (
+	debug           bool
+	reportUnmatched bool
+	reportUnsent    bool
+)
+
+// signal is a single place where a signal is sent, or received.
+type signal struct {
+	Name string
+	Pos  token.Pos
+	// Type is the payload type for senders, and the type the payload is decoded into for receivers.
+	// It's nil if we don't know it.
+	Type goTypes.Type
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	sent, received := identify(pass)
+	if debug {
+		fmt.Printf("Found %d signal senders and %d receivers\n", len(sent), len(received))
+	}
+	export(pass, sent, received)
+
+	// everything we know about: this package, and the packages it imports
+	importedSent, importedReceived := imported(pass)
+	allSent, allReceived := byName(sent, importedSent), byName(received, importedReceived)
+	resolver := handlers.NewResolver(pass.Fset, pass.Pkg)
+
+	for _, s := range sent {
+		receivedAs, isReceived := allReceived[s.Name]
+		if !isReceived {
+			// if we cannot see any receivers, the workflows are likely in a package we don't import
+			if reportUnmatched && len(allReceived) > 0 {
+				pass.Reportf(s.Pos, "Signal `%s` is sent, but no workflow receives it", s.Name)
+			}
+			continue
+		}
+		for _, expected := range receivedAs {
+			if !asttools.TypesMatch(resolver.Resolve(expected), s.Type) {
+				pass.Reportf(s.Pos, "Type of signal `%s` payload does not match the type it is received into\n"+
+					"\tExpected: %s,\n\t     got: %s", s.Name, expected, handlers.NewType(s.Type))
+			}
+		}
+	}
+	// a signal is received both where its channel is obtained, and where values are received from it
+	reportedUnsent := map[string]bool{}
+	for _, r := range received {
+		if _, isSent := allSent[r.Name]; !isSent {
+			if reportUnsent && len(allSent) > 0 && !reportedUnsent[r.Name] {
+				reportedUnsent[r.Name] = true
+				pass.Reportf(r.Pos, "Signal `%s` is received, but never sent", r.Name)
+			}
+			continue
+		}
+		// local senders have already been checked against all receivers,
+		// here we only check the senders from imported packages
+		for _, got := range importedSent[r.Name] {
+			if !asttools.TypesMatch(r.Type, resolver.Resolve(got)) {
+				pass.Reportf(r.Pos, "Type of signal `%s` payload does not match the type it is received into\n"+
+					"\tExpected: %s,\n\t     got: %s", r.Name, handlers.NewType(r.Type), got)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// export exports the signals of this package as a package fact,
+// so that they can be matched in the packages importing it.
+func export(pass *analysis.Pass, sent, received []signal) {
+	if len(sent) == 0 && len(received) == 0 {
+		return
+	}
+	pass.ExportPackageFact(&signalsFact{
+		Sent:     byName(sent, nil),
+		Received: byName(received, nil),
+	})
+}
+
+// imported returns the signals sent and received in all the packages imported by this one.
+func imported(pass *analysis.Pass) (sent, received map[string][]handlers.Type) {
+	sent, received = map[string][]handlers.Type{}, map[string][]handlers.Type{}
+	for _, f := range pass.AllPackageFacts() {
+		fact, ok := f.Fact.(*signalsFact)
+		if !ok || f.Package == pass.Pkg {
+			continue
+		}
+		for name, types := range fact.Sent {
+			sent[name] = append(sent[name], types...)
+		}
+		for name, types := range fact.Received {
+			received[name] = append(received[name], types...)
+		}
+	}
+	return sent, received
+}
+
+func identify(pass *analysis.Pass) (sent, received []signal) {
+	// signal channels, by the variable (or parameter) holding them
+	channels := map[goTypes.Object]string{}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) != len(n.Rhs) {
+					return true
+				}
+				for i, rhs := range n.Rhs {
+					if name, ok := channelName(pass, channels, rhs); ok {
+						if o := pass.TypesInfo.ObjectOf(asttools.IdentifierOf(n.Lhs[i])); o != nil {
+							channels[o] = name
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) != len(n.Values) {
+					return true
+				}
+				for i, rhs := range n.Values {
+					if name, ok := channelName(pass, channels, rhs); ok {
+						channels[pass.TypesInfo.ObjectOf(n.Names[i])] = name
+					}
+				}
+			case *ast.CallExpr:
+				if s, ok := asSent(pass, n); ok {
+					sent = append(sent, s)
+				}
+				if r, ok := asReceived(pass, channels, n); ok {
+					received = append(received, r)
+				}
+			}
+			return true
+		})
+	}
+	return sent, received
+}
+
+// channelName returns the name of the signal that the expression evaluates a channel of, if we can tell.
+func channelName(pass *analysis.Pass, channels map[goTypes.Object]string, e ast.Expr) (string, bool) {
+	switch e := e.(type) {
+	case *ast.ParenExpr:
+		return channelName(pass, channels, e.X)
+	case *ast.Ident:
+		name, ok := channels[pass.TypesInfo.ObjectOf(e)]
+		return name, ok
+	case *ast.CallExpr:
+		if !asttools.IsFuncCall(pass.TypesInfo, e, external.WorkflowPkg,
+			external.GetSignalChannel, external.GetSignalChannelWithOptions) {
+			return "", false
+		}
+		return asttools.StringValue(pass.TypesInfo, e.Args[1])
+	}
+	return "", false
+}
+
+// asSent returns the signal sent by the call, if it is a call sending a signal.
+func asSent(pass *analysis.Pass, call *ast.CallExpr) (signal, bool) {
+	var nameIdx, payloadIdx int
+	switch {
+	// client.SignalWorkflow(ctx, workflowID, runID, signalName, arg)
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ClientType, external.SignalWorkflow):
+		nameIdx, payloadIdx = 3, 4
+	// client.SignalWithStartWorkflow(ctx, workflowID, signalName, signalArg, options, workflow, args...)
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ClientType, external.SignalWithStartWorkflow):
+		nameIdx, payloadIdx = 2, 3
+	// workflow.SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
+	case asttools.IsFuncCall(pass.TypesInfo, call, external.WorkflowPkg, external.SignalExternalWorkflow):
+		nameIdx, payloadIdx = 3, 4
+	// childWorkflowFuture.SignalChildWorkflow(ctx, signalName, data)
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ChildWorkflowFutureType, external.SignalChildWorkflow):
+		nameIdx, payloadIdx = 1, 2
+	default:
+		return signal{}, false
+	}
+	if len(call.Args) <= payloadIdx {
+		return signal{}, false
+	}
+	name, ok := asttools.StringValue(pass.TypesInfo, call.Args[nameIdx])
+	if !ok {
+		if debug {
+			fmt.Printf("Signal name at %s is not a constant, ignoring\n", pass.Fset.Position(call.Pos()))
+		}
+		return signal{}, false
+	}
+	return signal{Name: name, Pos: call.Pos(), Type: pass.TypesInfo.TypeOf(call.Args[payloadIdx])}, true
+}
+
+// asReceived returns the signal received by the call, if it is a call on a signal channel:
+// either the channel itself being obtained, or a value being received from it.
+func asReceived(pass *analysis.Pass, channels map[goTypes.Object]string, call *ast.CallExpr) (signal, bool) {
+	if asttools.IsFuncCall(pass.TypesInfo, call, external.WorkflowPkg,
+		external.GetSignalChannel, external.GetSignalChannelWithOptions) {
+		name, ok := asttools.StringValue(pass.TypesInfo, call.Args[1])
+		if !ok {
+			return signal{}, false
+		}
+		return signal{Name: name, Pos: call.Pos()}, true
+	}
+
+	var targetIdx int
+	switch {
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ReceiveChannelType, external.Receive):
+		targetIdx = 1
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ReceiveChannelType, external.ReceiveWithTimeout):
+		targetIdx = 2
+	case asttools.IsMethodCall(pass.TypesInfo, call, external.ReceiveChannelType,
+		external.ReceiveAsync, external.ReceiveAsyncWithMoreFlag):
+		targetIdx = 0
+	default:
+		return signal{}, false
+	}
+	name, ok := channelName(pass, channels, call.Fun.(*ast.SelectorExpr).X)
+	if !ok || len(call.Args) <= targetIdx {
+		return signal{}, false
+	}
+	// values are received into pointers, we're interested in what they point to
+	ptr, ok := pass.TypesInfo.TypeOf(call.Args[targetIdx]).(*goTypes.Pointer)
+	if !ok {
+		return signal{}, false
+	}
+	return signal{Name: name, Pos: call.Pos(), Type: ptr.Elem()}, true
+}
+
+// byName merges the local signals with the imported ones, into payload types by signal name.
+func byName(local []signal, imported map[string][]handlers.Type) map[string][]handlers.Type {
+	result := map[string][]handlers.Type{}
+	for name, types := range imported {
+		result[name] = append(result[name], types...)
+	}
+	for _, s := range local {
+		result[s.Name] = append(result[s.Name], handlers.NewType(s.Type))
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package signals
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+// signalsFact records the signals sent and received in a package,
+// so that packages importing it can match senders with receivers.
+// Both maps go from the signal name to the payload types (empty if the type is unknown).
+type signalsFact struct {
+	Sent     map[string][]handlers.Type
+	Received map[string][]handlers.Type
+}
+
+func (f *signalsFact) AFact() {}
+
+func (f *signalsFact) String() string {
+	return fmt.Sprintf("signals(sent: %v, received: %v)", names(f.Sent), names(f.Received))
+}
+
+func names(m map[string][]handlers.Type) []string {
+	var result []string
+	for name := range m {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
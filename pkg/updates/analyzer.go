@@ -0,0 +1,183 @@
+package updates
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	goTypes "go/types"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/external"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/asttools"
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "TemporalioUpdates",
+	Doc:       "Checks Temporal.io update handlers and validators, and that updates are sent with the arguments of their handlers.",
+	Run:       run,
+	Flags:     flag.FlagSet{},
+	FactTypes: []analysis.Fact{new(updatesFact)},
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&debug, "debug-updates", false,
+		"Enable debug mode")
+	pflag.CommandLine.AddGoFlagSet(&Analyzer.Flags)
+}
+
+var debug bool
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	registered := identifyHandlers(pass)
+	if len(registered) > 0 {
+		pass.ExportPackageFact(&updatesFact{Handlers: registered})
+	}
+
+	// handlers from this package, and the packages it imports
+	known := map[string][]handlers.Handler{}
+	for name, hs := range registered {
+		known[name] = append(known[name], hs...)
+	}
+	for _, f := range pass.AllPackageFacts() {
+		if fact, ok := f.Fact.(*updatesFact); ok && f.Package != pass.Pkg {
+			for name, hs := range fact.Handlers {
+				known[name] = append(known[name], hs...)
+			}
+		}
+	}
+	resolver := handlers.NewResolver(pass.Fset, pass.Pkg)
+	if debug {
+		fmt.Printf("Found %d update handlers, %d known in total\n", len(registered), len(known))
+	}
+
+	// client.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{UpdateName: "add", Args: []interface{}{1}})
+	// we check the options wherever they are built, as they can be passed around before the call
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			t := pass.TypesInfo.TypeOf(lit)
+			if t == nil || goTypes.Unalias(t).String() != external.UpdateWorkflowOptionsType {
+				return true
+			}
+			name, ok := asttools.StringValue(pass.TypesInfo, asttools.FieldValue(lit, "UpdateName"))
+			if !ok {
+				return true
+			}
+			var args []ast.Expr
+			if argsExpr := asttools.FieldValue(lit, "Args"); argsExpr != nil {
+				argsLit, ok := argsExpr.(*ast.CompositeLit)
+				if !ok {
+					// built elsewhere, we can't tell what's in there
+					return true
+				}
+				args = argsLit.Elts
+			}
+			handlers.CheckArgs(pass, resolver, lit.Pos(), "update", name, known[name], args)
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// identifyHandlers finds and checks the update handlers (and their validators) set in this package.
+func identifyHandlers(pass *analysis.Pass) map[string][]handlers.Handler {
+	registered := map[string][]handlers.Handler{}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			// workflow.SetUpdateHandlerWithOptions(ctx, updateName, handler, opts)
+			if !asttools.IsFuncCall(pass.TypesInfo, call, external.WorkflowPkg,
+				external.SetUpdateHandler, external.SetUpdateHandlerWithOptions) {
+				return true
+			}
+			name, ok := asttools.StringValue(pass.TypesInfo, call.Args[1])
+			if !ok {
+				if debug {
+					fmt.Printf("Update name at %s is not a constant, ignoring\n", pass.Fset.Position(call.Pos()))
+				}
+				return true
+			}
+			sig, ok := pass.TypesInfo.TypeOf(call.Args[2]).Underlying().(*goTypes.Signature)
+			if !ok {
+				pass.Reportf(call.Args[2].Pos(), "Update handler `%s` must be a function", name)
+				return true
+			}
+			if !checkHandler(pass, call.Args[2], name, sig) {
+				return true
+			}
+			handler := handlers.FromSignature(sig)
+			registered[name] = append(registered[name], handler)
+
+			if len(call.Args) > 3 {
+				validator := asttools.FieldValue(call.Args[3], "Validator")
+				if validator != nil && !isNil(pass, validator) {
+					checkValidator(pass, validator, name, handler)
+				}
+			}
+			return true
+		})
+	}
+	return registered
+}
+
+// checkHandler checks that the handler takes a workflow.Context first, and returns either an error,
+// or a serializable result and an error, and that its arguments are serializable.
+// Returns false if the handler could never be registered.
+func checkHandler(pass *analysis.Pass, handler ast.Expr, name string, sig *goTypes.Signature) bool {
+	if sig.Params().Len() < 1 || !external.WorkflowCtx.MatchString(sig.Params().At(0).Type().String()) {
+		pass.Reportf(handler.Pos(), "Update handler `%s` must take a workflow.Context as the first argument", name)
+		return false
+	}
+	results := sig.Results()
+	if results.Len() < 1 || results.Len() > 2 || results.At(results.Len()-1).Type().String() != "error" {
+		pass.Reportf(handler.Pos(), "Update handler `%s` must return an error, or a serializable result and an error, "+
+			"but returns %s", name, results)
+		return false
+	}
+	if results.Len() == 2 {
+		if is, why := asttools.IsSerializable(results.At(0).Type()); !is {
+			pass.Reportf(handler.Pos(), "Update handler `%s` result (`%s`) is not serializable\n\treason: %s",
+				name, results.At(0).Type(), why)
+		}
+	}
+	for i := 1; i < sig.Params().Len(); i++ {
+		param := sig.Params().At(i)
+		if is, why := asttools.IsSerializable(param.Type()); !is {
+			pass.Reportf(handler.Pos(), "Update handler `%s` argument `%s` (`%s`) is not serializable\n\treason: %s",
+				name, param.Name(), param.Type(), why)
+		}
+	}
+	return true
+}
+
+// checkValidator checks that the validator returns only an error, and takes the same arguments as the handler
+// (a workflow.Context first is optional).
+func checkValidator(pass *analysis.Pass, validator ast.Expr, name string, handler handlers.Handler) {
+	sig, ok := pass.TypesInfo.TypeOf(validator).Underlying().(*goTypes.Signature)
+	if !ok {
+		pass.Reportf(validator.Pos(), "Validator of update `%s` must be a function", name)
+		return
+	}
+	if sig.Results().Len() != 1 || sig.Results().At(0).Type().String() != "error" {
+		pass.Reportf(validator.Pos(), "Validator of update `%s` must return only an error, but returns %s",
+			name, sig.Results())
+	}
+	v := handlers.FromSignature(sig)
+	if !handlers.SameParams(v, handler) {
+		pass.Reportf(validator.Pos(), "Validator of update `%s` must take the same arguments as its handler\n"+
+			"\tExpected: %v,\n\t     got: %v", name, handler.Params, v.Params)
+	}
+}
+
+func isNil(pass *analysis.Pass, e ast.Expr) bool {
+	return pass.TypesInfo.TypeOf(e) == goTypes.Typ[goTypes.UntypedNil]
+}
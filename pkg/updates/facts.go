@@ -0,0 +1,25 @@
+package updates
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/pkg/internal/handlers"
+)
+
+// updatesFact records the update handlers registered in a package, by update name,
+// so that UpdateWorkflow calls in the packages importing it can be checked.
+type updatesFact struct {
+	Handlers map[string][]handlers.Handler
+}
+
+func (f *updatesFact) AFact() {}
+
+func (f *updatesFact) String() string {
+	var names []string
+	for name := range f.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("updates(%v)", names)
+}
@@ -0,0 +1,17 @@
+package activities
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/worker"
+)
+
+// Register registers the activities of this package, under their public names.
+func Register(w worker.Worker) {
+	w.RegisterActivityWithOptions(Greet, activity.RegisterOptions{Name: "activities-greet"})
+}
+
+func Greet(ctx context.Context, name string) (string, error) {
+	return "Hello " + name, nil
+}
@@ -3,12 +3,16 @@ package test
 import (
 	"context"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
 	worker "go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/test/activities"
 )
 
 func Main() {
@@ -33,9 +37,64 @@ func Main() {
 	// or the other way around
 	tWorker.RegisterActivity(HelloWorldWorkflow)
 
+	// wrong, activities must return either an error, or a result and an error
+	tWorker.RegisterActivity(NoErrorActivity)
+	tWorker.RegisterActivity(TooManyResultsActivity)
+
 	// and an activity that's a struct with a method
 	tWorker.RegisterActivity(&SophisticatedHelloWorldActivity{})
 
+	// a struct with an exported method that's not an activity fails the registration...
+	tWorker.RegisterActivity(&MixedActivities{})
+	// ...unless such methods are explicitly skipped
+	tWorker.RegisterActivityWithOptions(&MixedActivities{}, activity.RegisterOptions{
+		Name:                       "Mixed_",
+		SkipInvalidStructFunctions: true,
+	})
+
+	// activities registered in another package
+	activities.Register(tWorker)
+
+	// an activity registered under a custom name
+	tWorker.RegisterActivityWithOptions(HelloVariadic, activity.RegisterOptions{Name: "greet-many"})
+
+	// wrong, a struct registered by value: methods with pointer receivers are not in its method set
+	tWorker.RegisterActivity(ValueActivities{})
+	// structs with value receivers only can be registered by value
+	tWorker.RegisterActivity(StatelessActivities{})
+
+	// wrong: the result has unexported fields only, callers get it back empty (reported at the declaration)
+	tWorker.RegisterActivity(ReceiptActivity)
+
+	// wrong: encoding/json cannot encode complex numbers, or functions
+	tWorker.RegisterActivity(MeasureActivity)
+
+	// wrong: both embedded structs have an ID, encoding/json drops both of them
+	tWorker.RegisterActivity(ShipOrderActivity)
+
+	// wrong: Money can be encoded, but not decoded back (no UnmarshalJSON), the activity gets zero values
+	tWorker.RegisterActivity(ChargeActivity)
+
+	// interfaces are decoded as maps (or fail to be decoded), reported with -TemporalioSerializableFields.strict-interfaces
+	tWorker.RegisterActivity(AuditActivity)
+
+	// recursive types are fine
+	tWorker.RegisterActivity(CategoryActivity)
+
+	// takes loosely typed payloads: large integers in them lose precision
+	tWorker.RegisterActivity(TrackActivity)
+
+	// secrets are stored in plain text in the workflow history, reported with -TemporalioSerializableFields.report-sensitive-data
+	tWorker.RegisterActivity(LoginActivity)
+
+	// wrong, registered twice: the worker panics at start
+	tWorker.RegisterActivity(NoErrorActivity)
+	// unless the previous registration is meant to be replaced
+	tWorker.RegisterActivityWithOptions(HelloVariadic, activity.RegisterOptions{
+		Name:                          "greet-many",
+		DisableAlreadyRegisteredCheck: true,
+	})
+
 	// start a workflow
 	executeWorkflow, err := temporalClient.ExecuteWorkflow(
 		context.Background(),
@@ -67,6 +126,17 @@ func HelloWorldWorkflow(ctx workflow.Context, name string) (string, error) {
 	// now mis-call the activity passing an int instead of a struct
 	errList = append(errList, workflow.ExecuteActivity(ctx, act.Greet2, 42).Get(ctx, &result))
 
+	// Greet2Param is not serializable, but it's reported once, at the Greet2 declaration
+	errList = append(errList, workflow.ExecuteActivity(ctx, act.Greet2, Greet2Param{}).Get(ctx, &result))
+
+	// wrong: int64 IDs in a map[string]any are decoded as float64, 9007199254740993 comes back as 9007199254740992
+	var orderID int64 = 9007199254740993
+	event := map[string]any{"action": "shipped", "order": orderID}
+	event["lines"] = []int64{1, 2}
+	errList = append(errList, workflow.ExecuteActivity(ctx, TrackActivity, event).Get(ctx, nil))
+	// changes after the call are not in the payload
+	event["retried"] = orderID
+
 	// a nil pointer to a struct can be untyped and it's "fine"
 	errList = append(errList, workflow.ExecuteActivity(ctx, act.Greet2, nil).Get(ctx, &result))
 
@@ -90,6 +160,25 @@ func HelloWorldWorkflow(ctx workflow.Context, name string) (string, error) {
 	// incorrect, resolved by activity name, too many arguments
 	errList = append(errList, workflow.ExecuteActivity(ctx, "HelloWorldActivity", name, "extra").Get(ctx, &result))
 
+	// incorrect, resolved by the custom name, and the variadic arguments must be strings
+	errList = append(errList, workflow.ExecuteActivity(ctx, "greet-many", ",", 1, 2).Get(ctx, &result))
+
+	// incorrect, resolved by the name registered in another package, too many arguments
+	errList = append(errList, workflow.ExecuteActivity(ctx, "activities-greet", name, "extra").Get(ctx, &result))
+
+	// incorrect, the activity returns a string, not an int
+	var count int
+	errList = append(errList, workflow.ExecuteActivity(ctx, HelloWorldActivity, name).Get(ctx, &count))
+
+	// incorrect, the result must be decoded into a pointer
+	future := workflow.ExecuteActivity(ctx, act.Greet, name)
+	errList = append(errList, future.Get(ctx, result))
+
+	// correct, the future is reassigned: the result is the one of the last activity
+	future = workflow.ExecuteActivity(ctx, HelloWorldActivity, name)
+	future = workflow.ExecuteActivity(ctx, act.Greet2, Greet2Param{})
+	errList = append(errList, future.Get(ctx, &result))
+
 	return result, errors.Join(errList...)
 }
 
@@ -114,3 +203,194 @@ func (s *SophisticatedHelloWorldActivity) Greet2(ctx context.Context, param Gree
 func HelloVariadic(ctx context.Context, sep string, names ...string) (string, error) {
 	return "Hello " + strings.Join(names, sep), nil
 }
+
+func BatchWorkflow(ctx workflow.Context, cursor string, batchSize int) error {
+	// correct, continues as itself with the same arguments
+	if cursor == "" {
+		return workflow.NewContinueAsNewError(ctx, BatchWorkflow, "next", batchSize)
+	}
+
+	// stale argument list, the batch size was added later
+	if cursor == "stale" {
+		return workflow.NewContinueAsNewError(ctx, BatchWorkflow, "next")
+	}
+
+	if cursor == "helper" {
+		return continueBatch(ctx, "next")
+	}
+
+	// continues as a different workflow
+	return workflow.NewContinueAsNewError(ctx, HelloWorldWorkflow, "World")
+}
+
+// a helper continuing workflows as new, called from a workflow: not reported, whatever it continues as
+func continueBatch(ctx workflow.Context, cursor string) error {
+	return workflow.NewContinueAsNewError(ctx, BatchWorkflow, cursor, 10)
+}
+
+func NoErrorActivity(ctx context.Context, name string) string {
+	return "Hello " + name
+}
+
+func TooManyResultsActivity(ctx context.Context, name string) (string, int, error) {
+	return "Hello " + name, len(name), nil
+}
+
+type MixedActivities struct{}
+
+func (m *MixedActivities) Greet(ctx context.Context, name string) (string, error) {
+	return "Hello " + name, nil
+}
+
+// Describe is a helper, not an activity
+func (m *MixedActivities) Describe() string {
+	return "mixed activities"
+}
+
+// Watch returns a channel, which the SDK refuses at registration
+func (m *MixedActivities) Watch(ctx context.Context) (chan string, error) {
+	return nil, nil
+}
+
+type StatelessActivities struct{}
+
+func (s StatelessActivities) Echo(ctx context.Context, message string) (string, error) {
+	return message, nil
+}
+
+type ValueActivities struct{}
+
+func (v ValueActivities) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (v *ValueActivities) Fetch(ctx context.Context, id string) (string, error) {
+	return id, nil
+}
+
+func ValueActivitiesWorkflow(ctx workflow.Context, id string) (string, error) {
+	var acts *ValueActivities
+	var result string
+	if err := workflow.ExecuteActivity(ctx, acts.Ping).Get(ctx, nil); err != nil {
+		return "", err
+	}
+	// Fetch will not be registered, as ValueActivities are registered by value
+	err := workflow.ExecuteActivity(ctx, acts.Fetch, id).Get(ctx, &result)
+	return result, err
+}
+
+type Receipt struct {
+	number string
+	total  float64
+}
+
+func ReceiptActivity(ctx context.Context, order string) (Receipt, error) {
+	return Receipt{number: order, total: 42}, nil
+}
+
+type Measurement struct {
+	Sensor string
+	Value  complex128
+	Probe  struct {
+		Calibrate func() error
+	}
+}
+
+func MeasureActivity(ctx context.Context, m Measurement) error {
+	return nil
+}
+
+type Category struct {
+	Name     string
+	Children []*Category
+	// skipped on purpose, fine
+	Loaded chan struct{} `json:"-"`
+}
+
+func CategoryActivity(ctx context.Context, root Category) (*Category, error) {
+	return &root, nil
+}
+
+type Audit struct {
+	ID        string
+	CreatedBy string
+}
+
+type OrderLine struct {
+	ID  string
+	Qty int
+}
+
+type Shipment struct {
+	Audit
+	OrderLine
+}
+
+func ShipOrderActivity(ctx context.Context, shipment Shipment) error {
+	return nil
+}
+
+type Money struct {
+	cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(m.cents, 10)), nil
+}
+
+func ChargeActivity(ctx context.Context, amount Money, byCurrency map[Currency]Money) error {
+	return nil
+}
+
+// Currency is a valid map key, as it implements both encoding.TextMarshaler and encoding.TextUnmarshaler
+type Currency struct {
+	code string
+}
+
+func (c Currency) MarshalText() ([]byte, error) {
+	return []byte(c.code), nil
+}
+
+func (c *Currency) UnmarshalText(text []byte) error {
+	c.code = string(text)
+	return nil
+}
+
+type AuditEntry struct {
+	Action  string
+	Details map[string]any
+	Cause   error
+}
+
+func AuditActivity(ctx context.Context, entry AuditEntry) error {
+	return nil
+}
+
+func TrackActivity(ctx context.Context, event map[string]any) error {
+	return nil
+}
+
+type Credentials struct {
+	Username string
+	Password string
+	APIKey   string `json:"api_key"`
+	Recovery string `temporal:"sensitive"`
+	// BusinessName is not an SSN
+	BusinessName string
+}
+
+func LoginActivity(ctx context.Context, credentials Credentials) error {
+	return nil
+}
+
+// activities started by names only known at run time cannot be resolved, and are not checked
+func DynamicWorkflow(ctx workflow.Context, activityName string) error {
+	if err := workflow.ExecuteActivity(ctx, activityName, "x").Get(ctx, nil); err != nil {
+		return err
+	}
+	return workflow.ExecuteActivity(ctx, dynamicActivityName(), "x").Get(ctx, nil)
+}
+
+func dynamicActivityName() string {
+	return "HelloWorldActivity"
+}
@@ -0,0 +1,69 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Run starts a worker registering everything itself: it's the only place that knows all of its registrations,
+// so the workflows and activities executed, but never registered, are reported here.
+func Run() error {
+	temporalClient, err := client.NewLazyClient(client.Options{})
+	if err != nil {
+		return err
+	}
+	w := worker.New(temporalClient, "notifications", worker.Options{})
+	w.RegisterWorkflow(NotifyWorkflow)
+	w.RegisterActivity(RenderActivity)
+	// the options can be held in a variable
+	archive := activity.RegisterOptions{Name: "archive"}
+	w.RegisterActivityWithOptions(ArchiveActivity, archive)
+	return w.Run(worker.InterruptCh())
+}
+
+// Status is the delivery status of a notification, by recipient.
+type Status struct {
+	Sent []string
+}
+
+func NotifyWorkflow(ctx workflow.Context, to string) error {
+	var status Status
+	err := workflow.SetQueryHandler(ctx, "status", func(recipient string) (*Status, error) {
+		return &status, nil
+	})
+	if err != nil {
+		return err
+	}
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{StartToCloseTimeout: time.Minute})
+	var body string
+	if err := workflow.ExecuteActivity(ctx, RenderActivity, to).Get(ctx, &body); err != nil {
+		return err
+	}
+	if err := workflow.ExecuteActivity(ctx, "archive", to).Get(ctx, nil); err != nil {
+		return err
+	}
+	// correct: the billing service's workers register the activities of its task queue, not this package's
+	billing := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{TaskQueue: "billing"})
+	if err := workflow.ExecuteActivity(billing, "ChargeActivity", to).Get(ctx, nil); err != nil {
+		return err
+	}
+	// wrong: SendActivity is never registered on the worker
+	return workflow.ExecuteActivity(ctx, SendActivity, to, body).Get(ctx, nil)
+}
+
+func RenderActivity(ctx context.Context, to string) (string, error) {
+	return "Hello " + to, nil
+}
+
+func SendActivity(ctx context.Context, to, body string) error {
+	return nil
+}
+
+func ArchiveActivity(ctx context.Context, to string) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/ikari-pl/golangci-lint-temporalio/test/notifications"
+)
+
+type Progress struct {
+	Done  int
+	Total int
+}
+
+func ProgressWorkflow(ctx workflow.Context) error {
+	progress := Progress{Total: 10}
+	err := workflow.SetQueryHandler(ctx, "progress", func() (Progress, error) {
+		return progress, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// handlers can take arguments
+	err = workflow.SetQueryHandler(ctx, "step", func(step int) (bool, error) {
+		return step < progress.Done, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// wrong: a query handler must return a result and an error
+	return workflow.SetQueryHandler(ctx, "broken", func() Progress {
+		return progress
+	})
+}
+
+type ReportProgress struct {
+	Percent float64
+}
+
+// another workflow with a "progress" query, of another type: queries match if any of the handlers accepts them
+func ReportProgressWorkflow(ctx workflow.Context) error {
+	return workflow.SetQueryHandler(ctx, "progress", func() (ReportProgress, error) {
+		return ReportProgress{}, nil
+	})
+}
+
+func QueryProgress(temporalClient client.Client) {
+	ctx := context.Background()
+
+	// correct
+	value, err := temporalClient.QueryWorkflow(ctx, "id", "", "progress")
+	if err != nil {
+		panic(err)
+	}
+	var progress Progress
+	_ = value.Get(&progress)
+
+	var report ReportProgress
+	_ = value.Get(&report)
+
+	// wrong result type
+	var done int
+	_ = value.Get(&done)
+
+	// wrong argument type, and too many of them
+	_, _ = temporalClient.QueryWorkflow(ctx, "id", "", "step", "first", 2)
+}
+
+func QueryNotifications(temporalClient client.Client) {
+	ctx := context.Background()
+
+	// handlers of other packages are checked too
+	value, err := temporalClient.QueryWorkflow(ctx, "id", "", "status", "to")
+	if err != nil {
+		panic(err)
+	}
+	var status notifications.Status
+	_ = value.Get(&status)
+
+	// wrong argument type
+	_, _ = temporalClient.QueryWorkflow(ctx, "id", "", "status", 42)
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RunReports runs the worker of the reporting workflows: the nightly report is started by a schedule,
+// and starts a child workflow for every section.
+func RunReports(c client.Client) error {
+	w := worker.New(c, reportsQueue, worker.Options{})
+	w.RegisterWorkflow(NightlyReportWorkflow)
+	w.RegisterWorkflow(ReportSectionWorkflow)
+	// nothing starts it, reported with -TemporalioSerializableFields.report-unused-registrations
+	// (unless it's listed in -TemporalioSerializableFields.external-workflows)
+	w.RegisterWorkflow(LegacyReportWorkflow)
+	return w.Run(worker.InterruptCh())
+}
+
+const reportsQueue = "reports"
+
+func ScheduleReports(ctx context.Context, c client.Client) error {
+	_, err := c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID: "nightly-report",
+		Action: &client.ScheduleWorkflowAction{
+			Workflow:  NightlyReportWorkflow,
+			Args:      []interface{}{"daily"},
+			TaskQueue: reportsQueue,
+		},
+	})
+	return err
+}
+
+func NightlyReportWorkflow(ctx workflow.Context, kind string) error {
+	for _, section := range []string{"sales", "stock"} {
+		// wrong: the section workflow takes a single string
+		err := workflow.ExecuteChildWorkflow(ctx, ReportSectionWorkflow, kind, section).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ReportSectionWorkflow(ctx workflow.Context, section string) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		TaskQueue:           reportsQueue,
+	})
+	// wrong: the workers of the reports queue don't register the activity (the ones of the test queue do)
+	return workflow.ExecuteActivity(ctx, HelloWorldActivity, section).Get(ctx, nil)
+}
+
+func LegacyReportWorkflow(ctx workflow.Context) error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+type Approval struct {
+	Approver string
+	Comment  string
+}
+
+func ApprovalWorkflow(ctx workflow.Context) (string, error) {
+	var approval Approval
+	workflow.GetSignalChannel(ctx, "approve").Receive(ctx, &approval)
+
+	// a channel held in a variable works as well
+	var reason string
+	rejections := workflow.GetSignalChannel(ctx, "reject")
+	rejections.ReceiveAsync(&reason)
+
+	// received, but nobody ever sends it, reported once with -TemporalioSignals.report-unsent-signals
+	var cancelled bool
+	cancels := workflow.GetSignalChannel(ctx, "cancel")
+	cancels.ReceiveAsync(&cancelled)
+
+	return approval.Approver, nil
+}
+
+func SendApprovals(temporalClient client.Client) {
+	ctx := context.Background()
+
+	// correct
+	_ = temporalClient.SignalWorkflow(ctx, "id", "", "approve", Approval{Approver: "me"})
+
+	// a pointer to the received type is fine too
+	_ = temporalClient.SignalWorkflow(ctx, "id", "", "approve", &Approval{Approver: "me"})
+
+	// wrong payload type
+	_ = temporalClient.SignalWorkflow(ctx, "id", "", "reject", 42)
+
+	// no workflow receives it
+	_ = temporalClient.SignalWorkflow(ctx, "id", "", "approved", Approval{})
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"errors"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+)
+
+func CounterWorkflow(ctx workflow.Context) (int, error) {
+	counter := 0
+	err := workflow.SetUpdateHandlerWithOptions(ctx, "add",
+		func(ctx workflow.Context, val int) (int, error) {
+			counter += val
+			return counter, nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(val int) error {
+				if val < 0 {
+					return errors.New("negative")
+				}
+				return nil
+			},
+		})
+	if err != nil {
+		return 0, err
+	}
+
+	// wrong: the validator takes different arguments than the handler, and returns more than an error
+	err = workflow.SetUpdateHandlerWithOptions(ctx, "reset",
+		func(ctx workflow.Context, to int) error {
+			counter = to
+			return nil
+		},
+		workflow.UpdateHandlerOptions{
+			Validator: func(to string) (bool, error) {
+				return to != "", nil
+			},
+		})
+	if err != nil {
+		return 0, err
+	}
+	return counter, nil
+}
+
+// another workflow with an "add" update, of another type: updates match if any of the handlers accepts them
+func GaugeWorkflow(ctx workflow.Context) (float64, error) {
+	gauge := 0.0
+	err := workflow.SetUpdateHandler(ctx, "add", func(ctx workflow.Context, val float64) error {
+		gauge += val
+		return nil
+	})
+	return gauge, err
+}
+
+func UpdateCounter(temporalClient client.Client) {
+	ctx := context.Background()
+
+	// correct
+	_, _ = temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID: "id",
+		UpdateName: "add",
+		Args:       []interface{}{1},
+	})
+	_, _ = temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID: "gauge",
+		UpdateName: "add",
+		Args:       []interface{}{1.5},
+	})
+
+	// wrong: a string instead of an int
+	_, _ = temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID: "id",
+		UpdateName: "add",
+		Args:       []interface{}{"one"},
+	})
+
+	// wrong: no arguments
+	_, _ = temporalClient.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID: "id",
+		UpdateName: "reset",
+	})
+}